@@ -0,0 +1,97 @@
+// Command admin runs maintenance tasks against the database configured for
+// the API: migrations, seeding and account administration.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"windsurf-project/internal/config"
+	"windsurf-project/internal/database"
+)
+
+const usage = `Usage: admin <command> [arguments]
+
+Commands:
+  migrate up                      Apply all pending migrations
+  migrate down [-steps N]         Roll back the last N migrations (default 1)
+  migrate status                  List migrations and whether they are applied
+  migrate create <name>           Create a new pair of migration files
+  seed -file <path>               Load interest groups and demo users from YAML
+  user create-admin -email <email> -username <name> [-password-stdin]
+                                  Create an admin, or promote an existing user;
+                                  the password is read from ADMIN_PASSWORD or,
+                                  with -password-stdin, from stdin
+  user deactivate (-email <email> | -id <id>)
+                                  Deactivate a user, revoke their tokens and
+                                  close their live connections
+  tokens purge-expired            Delete used and expired password reset tokens
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	action := ""
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	// Ctrl-C cancels the running queries.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var err error
+	switch command {
+	case "migrate":
+		err = runMigrate(ctx, action, args)
+	case "seed":
+		// seed has no action; its first argument is a flag.
+		if action != "" {
+			args = append([]string{action}, args...)
+		}
+		err = runSeed(ctx, args)
+	case "user":
+		err = runUser(ctx, action, args)
+	case "tokens":
+		err = runTokens(ctx, action, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		err = fmt.Errorf("unknown command %q", command)
+	}
+
+	if err != nil {
+		if err == errUsage {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		log.Fatalf("%s: %v", command, err)
+	}
+}
+
+var errUsage = fmt.Errorf("invalid usage")
+
+// connect loads the API configuration and opens the database, exactly as the
+// API server does.
+func connect() (*config.Config, *sql.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	db, err := database.NewPostgresDB(cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return cfg, db, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"windsurf-project/internal/database"
+)
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func runMigrate(ctx context.Context, action string, args []string) error {
+	if action == "create" {
+		return createMigration(args)
+	}
+
+	fs := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	steps := fs.Int("steps", 1, "number of migrations to roll back")
+	fs.Parse(args)
+
+	_, db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		if err := database.RunMigrations(ctx, db); err != nil {
+			return err
+		}
+		fmt.Println("Migrations are up to date")
+	case "down":
+		if *steps < 1 {
+			return fmt.Errorf("-steps must be at least 1")
+		}
+		reverted, err := database.RollbackMigrations(ctx, db, *steps)
+		if err != nil {
+			return fmt.Errorf("rolled back %d migration(s), then: %w", reverted, err)
+		}
+		fmt.Printf("Rolled back %d migration(s)\n", reverted)
+	case "status":
+		statuses, err := database.GetMigrationStatus(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		return errUsage
+	}
+
+	return nil
+}
+
+// createMigration writes empty up and down files with the next version
+// number. They are picked up by the next build of the API.
+func createMigration(args []string) error {
+	fs := flag.NewFlagSet("migrate create", flag.ExitOnError)
+	dir := fs.String("dir", "internal/database/migrations", "migrations directory")
+	// The name may come before or after the flags.
+	name := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if name == "" && fs.NArg() == 1 {
+		name = fs.Arg(0)
+	} else if name == "" || fs.NArg() != 0 {
+		return errUsage
+	}
+	if !migrationName.MatchString(name) {
+		return fmt.Errorf("migration name must be lowercase letters, digits and underscores")
+	}
+
+	entries, err := os.ReadDir(*dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", *dir, err)
+	}
+	next := 1
+	for _, entry := range entries {
+		var version int
+		if _, err := fmt.Sscanf(entry.Name(), "%d_", &version); err == nil && version >= next {
+			next = version + 1
+		}
+	}
+
+	prefix := fmt.Sprintf("%04d_%s", next, name)
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(*dir, prefix+"."+direction+".sql")
+		content := "-- " + prefix + " (" + direction + ")\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Println("Created " + path)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+	"windsurf-project/internal/service"
+)
+
+// seedFile is the YAML layout read by the seed command. See
+// seeds/demo.yaml for an example.
+type seedFile struct {
+	InterestGroups []struct {
+		Name        string  `yaml:"name"`
+		Description *string `yaml:"description"`
+		IconURL     *string `yaml:"icon_url"`
+	} `yaml:"interest_groups"`
+	Users []seedUser `yaml:"users"`
+}
+
+type seedUser struct {
+	Email     string   `yaml:"email"`
+	Username  string   `yaml:"username"`
+	Password  string   `yaml:"password"`
+	FirstName *string  `yaml:"first_name"`
+	LastName  *string  `yaml:"last_name"`
+	Interests []string `yaml:"interests"`
+	Admin     bool     `yaml:"admin"`
+}
+
+// runSeed loads interest groups and users from a YAML file. It can be run
+// repeatedly: groups are updated in place and existing users are skipped.
+func runSeed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	file := fs.String("file", "seeds/demo.yaml", "YAML seed file")
+	fs.Parse(args)
+
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("failed to read seed file: %w", err)
+	}
+	var seed seedFile
+	if err := yaml.Unmarshal(content, &seed); err != nil {
+		return fmt.Errorf("failed to parse seed file: %w", err)
+	}
+
+	cfg, db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	userRepo := repository.NewUserRepository(db)
+	interestRepo := repository.NewInterestRepository(db)
+	authService := service.NewAuthService(userRepo, repository.NewTokenRepository(db), interestRepo, repository.NewUnitOfWork(db), cfg.JWTSecret)
+
+	for _, g := range seed.InterestGroups {
+		if g.Name == "" {
+			return fmt.Errorf("interest group without a name")
+		}
+		if _, err := interestRepo.Upsert(ctx, g.Name, g.Description, g.IconURL); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Seeded %d interest group(s)\n", len(seed.InterestGroups))
+
+	interestIDs, err := interestRepo.IDsByName(ctx)
+	if err != nil {
+		return err
+	}
+
+	created := 0
+	for _, u := range seed.Users {
+		if existing, _ := userRepo.GetByEmail(ctx, u.Email); existing != nil {
+			fmt.Printf("Skipping %s: already exists\n", u.Email)
+			continue
+		}
+
+		req := &models.RegisterRequest{
+			Email:     u.Email,
+			Username:  u.Username,
+			Password:  u.Password,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+		}
+		for _, name := range u.Interests {
+			id, ok := interestIDs[name]
+			if !ok {
+				return fmt.Errorf("user %s: unknown interest group %q", u.Email, name)
+			}
+			req.Interests = append(req.Interests, id)
+		}
+
+		user, err := createUser(ctx, authService, req)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", u.Email, err)
+		}
+		if u.Admin {
+			if err := userRepo.SetAdmin(ctx, user.ID, true); err != nil {
+				return err
+			}
+		}
+		created++
+	}
+	fmt.Printf("Created %d user(s)\n", created)
+
+	return nil
+}
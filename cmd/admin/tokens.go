@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"windsurf-project/internal/repository"
+)
+
+func runTokens(ctx context.Context, action string, args []string) error {
+	if action != "purge-expired" || len(args) > 0 {
+		return errUsage
+	}
+
+	_, db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	purged, err := repository.NewTokenRepository(db).PurgeExpiredResetTokens(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Purged %d password reset token(s)\n", purged)
+
+	return nil
+}
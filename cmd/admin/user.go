@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/realtime"
+	"windsurf-project/internal/repository"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/validator"
+)
+
+func runUser(ctx context.Context, action string, args []string) error {
+	switch action {
+	case "create-admin":
+		return createAdmin(ctx, args)
+	case "deactivate":
+		return deactivateUser(ctx, args)
+	default:
+		return errUsage
+	}
+}
+
+// createAdmin creates an admin account, or promotes the existing account
+// with that email, in which case the username and password are ignored. The
+// password is never a flag, which would show up in the process list and
+// shell history; see readPassword.
+func createAdmin(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("user create-admin", flag.ExitOnError)
+	email := fs.String("email", "", "email address")
+	username := fs.String("username", "", "username for a new account")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password for a new account from stdin instead of "+passwordEnv)
+	fs.Parse(args)
+
+	if err := validator.ValidateEmail(*email); err != nil {
+		return err
+	}
+
+	cfg, db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	userRepo := repository.NewUserRepository(db)
+
+	user, _ := userRepo.GetByEmail(ctx, *email)
+	if user == nil {
+		password, err := readPassword(*passwordStdin)
+		if err != nil {
+			return err
+		}
+
+		authService := service.NewAuthService(userRepo, repository.NewTokenRepository(db), repository.NewInterestRepository(db), repository.NewUnitOfWork(db), cfg.JWTSecret)
+		user, err = createUser(ctx, authService, &models.RegisterRequest{
+			Email:    *email,
+			Username: *username,
+			Password: password,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created user %d (%s)\n", user.ID, user.Email)
+	}
+
+	if err := userRepo.SetAdmin(ctx, user.ID, true); err != nil {
+		return err
+	}
+	fmt.Printf("User %d (%s) is now an admin\n", user.ID, user.Email)
+
+	return nil
+}
+
+// passwordEnv holds the password for create-admin unless -password-stdin is
+// given.
+const passwordEnv = "ADMIN_PASSWORD"
+
+// readPassword returns the first line of stdin, e.g. piped from a secrets
+// manager, or else the value of passwordEnv.
+func readPassword(fromStdin bool) (string, error) {
+	if !fromStdin {
+		return os.Getenv(passwordEnv), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// deactivateUser suspends an account, revokes its tokens and closes its
+// live connections on every API replica, the same as a moderator
+// suspension.
+func deactivateUser(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("user deactivate", flag.ExitOnError)
+	email := fs.String("email", "", "email address")
+	id := fs.Int("id", 0, "user id")
+	fs.Parse(args)
+
+	if (*email == "") == (*id == 0) {
+		return fmt.Errorf("pass exactly one of -email or -id")
+	}
+
+	cfg, db, err := connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	userRepo := repository.NewUserRepository(db)
+
+	var user *models.User
+	if *email != "" {
+		user, err = userRepo.GetByEmail(ctx, *email)
+	} else {
+		user, err = userRepo.GetByID(ctx, *id)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := userRepo.Suspend(ctx, user.ID); err != nil {
+		return err
+	}
+	fmt.Printf("Deactivated user %d (%s)\n", user.ID, user.Email)
+
+	// The hub is only used to publish on the bus the API replicas listen on.
+	hub := realtime.NewHub(realtime.NewPostgresBus(db, cfg.DatabaseURL), repository.NewPresenceRepository(db, 3*realtime.HeartbeatInterval))
+	if err := hub.DisconnectUser(user.ID); err != nil {
+		return fmt.Errorf("user is deactivated, but closing their live connections failed: %w", err)
+	}
+
+	return nil
+}
+
+// createUser validates and registers an account with the same rules as the
+// registration endpoint.
+func createUser(ctx context.Context, authService *service.AuthService, req *models.RegisterRequest) (*models.User, error) {
+	if err := validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := authService.Register(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"os"
 
@@ -10,6 +12,9 @@ import (
 )
 
 func main() {
+	migrate := flag.String("migrate", "auto", "schema migrations at startup: auto (apply pending), check (refuse to start unless up to date) or skip")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -23,9 +28,20 @@ func main() {
 	}
 	defer db.Close()
 
-	// Run migrations
-	if err := database.RunMigrations(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Run or verify migrations
+	switch *migrate {
+	case "auto":
+		if err := database.RunMigrations(context.Background(), db); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+	case "check":
+		if err := database.CheckMigrations(context.Background(), db); err != nil {
+			log.Fatalf("Schema check failed: %v", err)
+		}
+	case "skip":
+		log.Printf("Skipping migrations")
+	default:
+		log.Fatalf("Invalid -migrate mode %q: use auto, check or skip", *migrate)
 	}
 
 	// Initialize and start API server
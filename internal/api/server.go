@@ -1,29 +1,47 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/gorilla/mux"
 
 	"windsurf-project/internal/config"
 	"windsurf-project/internal/handlers"
+	"windsurf-project/internal/lifecycle"
 	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/realtime"
 	"windsurf-project/internal/repository"
 	"windsurf-project/internal/service"
 )
 
 type Server struct {
-	config  *config.Config
-	db      *sql.DB
-	router  *mux.Router
+	config     *config.Config
+	db         *sql.DB
+	router     *mux.Router
+	httpServer *http.Server
+	background *lifecycle.Registry
+
+	// stop is closed on shutdown to end background jobs and live
+	// connections.
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 func NewServer(cfg *config.Config, db *sql.DB) *Server {
 	s := &Server{
-		config: cfg,
-		db:     db,
-		router: mux.NewRouter(),
+		config:     cfg,
+		db:         db,
+		router:     mux.NewRouter(),
+		background: lifecycle.NewRegistry(),
+		stop:       make(chan struct{}),
 	}
 
 	s.setupRoutes()
@@ -32,14 +50,63 @@ func NewServer(cfg *config.Config, db *sql.DB) *Server {
 
 func (s *Server) setupRoutes() {
 	// Initialize repositories
+	repository.ConfigureTimeouts(repository.Timeouts{
+		Default: s.config.QueryTimeout,
+		Search:  s.config.SearchTimeout,
+		Batch:   s.config.BatchTimeout,
+	})
 	userRepo := repository.NewUserRepository(s.db)
+	tokenRepo := repository.NewTokenRepository(s.db)
+	interestRepo := repository.NewInterestRepository(s.db)
+	languageRepo := repository.NewLanguageRepository(s.db)
+	recRepo := repository.NewRecommendationRepository(s.db)
+	followRepo := repository.NewFollowRepository(s.db)
+	blockRepo := repository.NewBlockRepository(s.db)
+	reportRepo := repository.NewReportRepository(s.db)
+	messageRepo := repository.NewMessageRepository(s.db)
+	presenceRepo := repository.NewPresenceRepository(s.db, 3*realtime.HeartbeatInterval)
+	notificationRepo := repository.NewNotificationRepository(s.db)
+	digestRepo := repository.NewDigestRepository(s.db)
+	searchRepo := repository.NewSearchRepository(s.db)
+	ticketRepo := repository.NewLiveTicketRepository(s.db)
+
+	// Realtime hub, with Postgres LISTEN/NOTIFY as the bus between replicas
+	hub := realtime.NewHub(realtime.NewPostgresBus(s.db, s.config.DatabaseURL), presenceRepo)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, s.config.JWTSecret)
-	emailService := service.NewEmailService(s.config)
+	authService := service.NewAuthService(userRepo, tokenRepo, interestRepo, repository.NewUnitOfWork(s.db), s.config.JWTSecret)
+	ticketService := service.NewLiveTicketService(userRepo, ticketRepo)
+	emailService := service.NewEmailService(s.config, s.background)
+	userService := service.NewUserService(userRepo)
+	languageService := service.NewLanguageService(languageRepo)
+	recService := service.NewRecommendationService(recRepo)
+	notificationService := service.NewNotificationService(userRepo, blockRepo, notificationRepo, emailService, hub)
+	followService := service.NewFollowService(userRepo, followRepo, repository.NewUnitOfWork(s.db), notificationService)
+	blockService := service.NewBlockService(userRepo, blockRepo)
+	moderationService := service.NewModerationService(userRepo, reportRepo, emailService, hub)
+	messageService := service.NewMessageService(userRepo, followRepo, blockRepo, messageRepo, hub, s.config.FirstContactDailyLimit)
+	searchService := service.NewSearchService(searchRepo)
+	digestService := service.NewDigestService(digestRepo, emailService, s.config.JWTSecret, s.config.APIURL)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService, emailService)
+	userHandler := handlers.NewUserHandler(userService)
+	languageHandler := handlers.NewLanguageHandler(languageService)
+	recHandler := handlers.NewRecommendationHandler(recService)
+	followHandler := handlers.NewFollowHandler(followService)
+	blockHandler := handlers.NewBlockHandler(blockService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	messageHandler := handlers.NewMessageHandler(messageService)
+	realtimeHandler := handlers.NewRealtimeHandler(authService, ticketService, messageService, hub)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	streamHandler := handlers.NewStreamHandler(authService, ticketService, notificationService, messageService, hub)
+	digestHandler := handlers.NewDigestHandler(digestService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+
+	// Background jobs
+	s.background.Go(func() { recService.Run(s.config.RecommendationInterval, s.stop) })
+	s.background.Go(func() { digestService.Run(s.config.DigestInterval, s.stop) })
+	s.background.Go(func() { hub.Run(s.stop) })
 
 	// API router with middleware
 	api := s.router.PathPrefix("/api").Subrouter()
@@ -57,11 +124,89 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/auth/login", authHandler.Login).Methods("POST")
 	api.HandleFunc("/auth/password-reset/request", authHandler.RequestPasswordReset).Methods("POST")
 	api.HandleFunc("/auth/password-reset/confirm", authHandler.ResetPassword).Methods("POST")
+	api.HandleFunc("/digest/unsubscribe", digestHandler.ConfirmUnsubscribe).Methods("GET")
+	api.HandleFunc("/digest/unsubscribe", digestHandler.Unsubscribe).Methods("POST")
+
+	// WebSocket gateway and SSE stream (authenticate the token or ticket themselves)
+	api.HandleFunc("/ws", realtimeHandler.Serve).Methods("GET")
+	api.HandleFunc("/stream", streamHandler.Stream).Methods("GET")
 
 	// Protected routes (require authentication)
 	protected := api.PathPrefix("/auth").Subrouter()
 	protected.Use(middleware.Auth(authService))
 	protected.HandleFunc("/profile", authHandler.GetProfile).Methods("GET")
+	protected.HandleFunc("/live-ticket", realtimeHandler.Ticket).Methods("POST")
+
+	users := api.PathPrefix("/users").Subrouter()
+	users.Use(middleware.Auth(authService))
+	users.HandleFunc("/me/location", userHandler.GetLocation).Methods("GET")
+	users.HandleFunc("/me/location", userHandler.UpdateLocation).Methods("PUT")
+	users.HandleFunc("/nearby", userHandler.Nearby).Methods("GET")
+	users.HandleFunc("/recommendations", recHandler.List).Methods("GET")
+	users.HandleFunc("/me/privacy", followHandler.UpdatePrivacy).Methods("PUT")
+	users.HandleFunc("/me/follow-requests", followHandler.Requests).Methods("GET")
+	users.HandleFunc("/me/follow-requests/{id:[0-9]+}/accept", followHandler.AcceptRequest).Methods("POST")
+	users.HandleFunc("/me/follow-requests/{id:[0-9]+}", followHandler.RejectRequest).Methods("DELETE")
+	users.HandleFunc("/{id:[0-9]+}", followHandler.GetProfile).Methods("GET")
+	users.HandleFunc("/{id:[0-9]+}/follow", followHandler.Follow).Methods("POST")
+	users.HandleFunc("/{id:[0-9]+}/follow", followHandler.Unfollow).Methods("DELETE")
+	users.HandleFunc("/{id:[0-9]+}/followers", followHandler.Followers).Methods("GET")
+	users.HandleFunc("/{id:[0-9]+}/following", followHandler.Following).Methods("GET")
+	users.HandleFunc("/me/blocks", blockHandler.ListBlocked).Methods("GET")
+	users.HandleFunc("/me/mutes", blockHandler.ListMuted).Methods("GET")
+	users.HandleFunc("/{id:[0-9]+}/block", blockHandler.Block).Methods("POST")
+	users.HandleFunc("/{id:[0-9]+}/block", blockHandler.Unblock).Methods("DELETE")
+	users.HandleFunc("/{id:[0-9]+}/mute", blockHandler.Mute).Methods("POST")
+	users.HandleFunc("/{id:[0-9]+}/mute", blockHandler.Unmute).Methods("DELETE")
+	users.HandleFunc("/me/messaging", messageHandler.GetSettings).Methods("GET")
+	users.HandleFunc("/me/messaging", messageHandler.UpdateSettings).Methods("PUT")
+	users.HandleFunc("/me/digest", digestHandler.GetSettings).Methods("GET")
+	users.HandleFunc("/me/digest", digestHandler.UpdateSettings).Methods("PUT")
+	users.HandleFunc("/me/languages", languageHandler.GetProfile).Methods("GET")
+	users.HandleFunc("/me/languages", languageHandler.UpdateProfile).Methods("PUT")
+
+	languages := api.PathPrefix("/languages").Subrouter()
+	languages.Use(middleware.Auth(authService))
+	languages.HandleFunc("/partners", languageHandler.Partners).Methods("GET")
+
+	messages := api.PathPrefix("/messages").Subrouter()
+	messages.Use(middleware.Auth(authService))
+	messages.HandleFunc("", messageHandler.SendToUser).Methods("POST")
+	messages.HandleFunc("/unread", messageHandler.UnreadCount).Methods("GET")
+
+	conversations := api.PathPrefix("/conversations").Subrouter()
+	conversations.Use(middleware.Auth(authService))
+	conversations.HandleFunc("", messageHandler.ListConversations).Methods("GET")
+	conversations.HandleFunc("/{id:[0-9]+}/messages", messageHandler.ListMessages).Methods("GET")
+	conversations.HandleFunc("/{id:[0-9]+}/messages", messageHandler.Send).Methods("POST")
+	conversations.HandleFunc("/{id:[0-9]+}/read", messageHandler.MarkRead).Methods("POST")
+
+	search := api.PathPrefix("/search").Subrouter()
+	search.Use(middleware.Auth(authService))
+	search.HandleFunc("", searchHandler.Search).Methods("GET")
+
+	reports := api.PathPrefix("/reports").Subrouter()
+	reports.Use(middleware.Auth(authService))
+	reports.HandleFunc("", moderationHandler.CreateReport).Methods("POST")
+
+	notifications := api.PathPrefix("/notifications").Subrouter()
+	notifications.Use(middleware.Auth(authService))
+	notifications.HandleFunc("", notificationHandler.List).Methods("GET")
+	notifications.HandleFunc("/unread-count", notificationHandler.UnreadCount).Methods("GET")
+	notifications.HandleFunc("/read-all", notificationHandler.MarkAllRead).Methods("POST")
+	notifications.HandleFunc("/preferences", notificationHandler.GetPreferences).Methods("GET")
+	notifications.HandleFunc("/preferences", notificationHandler.UpdatePreferences).Methods("PUT")
+	notifications.HandleFunc("/{id:[0-9]+}/read", notificationHandler.MarkRead).Methods("POST")
+
+	// Admin routes (require an administrator account)
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.Auth(authService))
+	admin.Use(middleware.Admin(authService))
+	admin.HandleFunc("/reports", moderationHandler.ListReports).Methods("GET")
+	admin.HandleFunc("/reports/{id:[0-9]+}", moderationHandler.GetReport).Methods("GET")
+	admin.HandleFunc("/reports/{id:[0-9]+}/assign", moderationHandler.AssignReport).Methods("POST")
+	admin.HandleFunc("/reports/{id:[0-9]+}/resolve", moderationHandler.ResolveReport).Methods("POST")
+	admin.HandleFunc("/reports/{id:[0-9]+}/actions", moderationHandler.TakeAction).Methods("POST")
 
 	// Handle OPTIONS for CORS preflight
 	s.router.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -69,6 +214,73 @@ func (s *Server) setupRoutes() {
 	})
 }
 
+// OnShutdown registers a hook that runs after the HTTP server has drained.
+// Hooks run in reverse registration order.
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.background.OnShutdown(name, fn)
+}
+
+// Start serves HTTP on addr until SIGINT or SIGTERM, then shuts down
+// gracefully. It returns nil after a clean shutdown.
 func (s *Server) Start(addr string) error {
-	return http.ListenAndServe(addr, s.router)
+	s.httpServer = &http.Server{
+		Addr:           addr,
+		Handler:        s.router,
+		ReadTimeout:    s.config.ReadTimeout,
+		WriteTimeout:   s.config.WriteTimeout,
+		IdleTimeout:    s.config.IdleTimeout,
+		MaxHeaderBytes: s.config.MaxHeaderBytes,
+	}
+	// Live connections never go idle on their own, so they are ended as
+	// soon as shutdown begins; clients reconnect to another replica.
+	s.httpServer.RegisterOnShutdown(s.closeStop)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		s.closeStop()
+		return err
+	case <-ctx.Done():
+		log.Printf("Shutting down, draining for up to %s...", s.config.ShutdownTimeout)
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancelShutdown()
+
+	return s.Shutdown(shutdownCtx)
+}
+
+// Shutdown stops accepting connections, waits for in-flight requests, runs
+// the shutdown hooks and waits for background work such as queued emails
+// and running jobs, all within ctx.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var drainErr error
+	if s.httpServer != nil {
+		if err := s.httpServer.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			drainErr = fmt.Errorf("failed to drain HTTP server: %w", err)
+		}
+	}
+	s.closeStop()
+
+	// Hooks and background work still get their chance after a failed
+	// drain; with ctx already expired they are only started, not awaited.
+	if err := errors.Join(drainErr, s.background.Shutdown(ctx)); err != nil {
+		return err
+	}
+
+	log.Printf("Shutdown complete")
+	return nil
+}
+
+func (s *Server) closeStop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
 }
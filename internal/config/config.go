@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +18,28 @@ type Config struct {
 	SMTPPassword    string
 	FrontendURL     string
 	Environment     string
+
+	// APIURL is the public base URL of this API, used in links that must
+	// reach the API directly, such as one-click unsubscribe.
+	APIURL string
+
+	// HTTP server limits. ShutdownTimeout bounds how long a SIGTERM waits
+	// for in-flight requests and background work.
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	MaxHeaderBytes  int
+	ShutdownTimeout time.Duration
+
+	// Per-operation database deadlines: ordinary queries, search and
+	// nearby lookups, and background batch jobs.
+	QueryTimeout  time.Duration
+	SearchTimeout time.Duration
+	BatchTimeout  time.Duration
+
+	RecommendationInterval time.Duration
+	DigestInterval         time.Duration
+	FirstContactDailyLimit int
 }
 
 func Load() (*Config, error) {
@@ -31,6 +55,22 @@ func Load() (*Config, error) {
 		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 		FrontendURL:  getEnv("FRONTEND_URL", "http://localhost:3000"),
 		Environment:  getEnv("ENVIRONMENT", "development"),
+
+		APIURL: getEnv("API_URL", "http://localhost:8080"),
+
+		ReadTimeout:     getDurationEnv("HTTP_READ_TIMEOUT", 15*time.Second),
+		WriteTimeout:    getDurationEnv("HTTP_WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:     getDurationEnv("HTTP_IDLE_TIMEOUT", 60*time.Second),
+		MaxHeaderBytes:  getIntEnv("HTTP_MAX_HEADER_BYTES", 1<<20),
+		ShutdownTimeout: getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		QueryTimeout:  getDurationEnv("DB_QUERY_TIMEOUT", 5*time.Second),
+		SearchTimeout: getDurationEnv("DB_SEARCH_TIMEOUT", 10*time.Second),
+		BatchTimeout:  getDurationEnv("DB_BATCH_TIMEOUT", 2*time.Minute),
+
+		RecommendationInterval: getDurationEnv("RECOMMENDATION_INTERVAL", time.Hour),
+		DigestInterval:         getDurationEnv("DIGEST_INTERVAL", time.Hour),
+		FirstContactDailyLimit: getIntEnv("FIRST_CONTACT_DAILY_LIMIT", 10),
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -56,3 +96,21 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getIntEnv(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultValue
+}
@@ -1,63 +1,371 @@
 package database
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"embed"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 )
 
-func RunMigrations(db *sql.DB) error {
-	migrations := []string{
-		`CREATE TABLE IF NOT EXISTS users (
-			id SERIAL PRIMARY KEY,
-			email VARCHAR(255) UNIQUE NOT NULL,
-			username VARCHAR(100) UNIQUE NOT NULL,
-			password_hash VARCHAR(255) NOT NULL,
-			first_name VARCHAR(100),
-			last_name VARCHAR(100),
-			bio TEXT,
-			avatar_url VARCHAR(500),
-			is_verified BOOLEAN DEFAULT FALSE,
-			is_active BOOLEAN DEFAULT TRUE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_users_email ON users(email)`,
-		`CREATE INDEX IF NOT EXISTS idx_users_username ON users(username)`,
-		`CREATE TABLE IF NOT EXISTS password_reset_tokens (
-			id SERIAL PRIMARY KEY,
-			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			token VARCHAR(255) UNIQUE NOT NULL,
-			expires_at TIMESTAMP NOT NULL,
-			used BOOLEAN DEFAULT FALSE,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_token ON password_reset_tokens(token)`,
-		`CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id)`,
-		`CREATE TABLE IF NOT EXISTS interest_groups (
-			id SERIAL PRIMARY KEY,
-			name VARCHAR(100) UNIQUE NOT NULL,
-			description TEXT,
-			icon_url VARCHAR(500),
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS user_interests (
-			user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			interest_id INTEGER NOT NULL REFERENCES interest_groups(id) ON DELETE CASCADE,
-			joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			PRIMARY KEY (user_id, interest_id)
-		)`,
-		`INSERT INTO interest_groups (name, description) VALUES 
-			('Coworking', 'Connect with professionals and digital nomads'),
-			('Photography', 'Share and discuss photography'),
-			('Food', 'Discover local cuisine and restaurants'),
-			('Languages', 'Practice and learn new languages')
-		ON CONFLICT (name) DO NOTHING`,
-	}
-
-	for _, migration := range migrations {
-		if _, err := db.Exec(migration); err != nil {
-			return fmt.Errorf("migration failed: %w", err)
+// Migrations live in migrations/ as NNNN_name.up.sql and NNNN_name.down.sql
+// and are compiled into the binary. Applied versions are recorded in
+// schema_migrations with a checksum of their up file, so an applied file
+// that is later edited is detected instead of silently diverging.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// migrationLockKey identifies the Postgres advisory lock that serializes
+// migrations across replicas and the admin CLI.
+const migrationLockKey int64 = 0x6d69677261746531
+
+// Migration is one numbered schema change.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus describes a known migration and whether it is applied.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// LoadMigrations reads the embedded migrations in version order.
+func LoadMigrations() ([]*Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// RunMigrations applies every pending migration in order, each in its own
+// transaction. It refuses to run if an applied migration has been edited or
+// is missing from this build. An advisory lock is held throughout, so when
+// several replicas start together one migrates and the others wait, then
+// find nothing pending.
+//
+// Every migration up to 0013 uses IF NOT EXISTS, so databases created before
+// schema_migrations existed adopt them without changes.
+func RunMigrations(ctx context.Context, db *sql.DB) error {
+	return withMigrationLock(ctx, db, func() error {
+		return runMigrations(ctx, db)
+	})
+}
+
+func runMigrations(ctx context.Context, db *sql.DB) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	if err := ensureMigrationTable(ctx, db); err != nil {
+		return err
+	}
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err := verifyApplied(migrations, applied); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RollbackMigrations reverts the latest steps applied migrations, newest
+// first, each in its own transaction, under the migration lock. It returns
+// how many were reverted, which is fewer than steps when fewer are applied
+// or when a revert fails.
+func RollbackMigrations(ctx context.Context, db *sql.DB, steps int) (int, error) {
+	var reverted int
+	err := withMigrationLock(ctx, db, func() error {
+		var err error
+		reverted, err = rollbackMigrations(ctx, db, steps)
+		return err
+	})
+	return reverted, err
+}
+
+func rollbackMigrations(ctx context.Context, db *sql.DB, steps int) (int, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	if err := ensureMigrationTable(ctx, db); err != nil {
+		return 0, err
+	}
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+	if err := verifyApplied(migrations, applied); err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	for i := len(migrations) - 1; i >= 0 && reverted < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if err := revertMigration(ctx, db, m); err != nil {
+			return reverted, err
+		}
+		reverted++
+	}
+
+	return reverted, nil
+}
+
+// CheckMigrations returns an error unless the database schema is exactly at
+// the version this build expects: nothing pending, nothing unknown applied
+// and no applied migration modified. It only reads, and takes no lock, so
+// it can run against a database that another replica is migrating.
+func CheckMigrations(ctx context.Context, db *sql.DB) error {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err := verifyApplied(migrations, applied); err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("database schema is behind: %d migration(s) pending", pending)
+	}
+
+	return nil
+}
+
+// GetMigrationStatus lists every known migration with the time it was
+// applied, if it was.
+func GetMigrationStatus(ctx context.Context, db *sql.DB) ([]*MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := &MigrationStatus{Version: m.Version, Name: m.Name}
+		if a, ok := applied[m.Version]; ok {
+			appliedAt := a.appliedAt
+			status.AppliedAt = &appliedAt
 		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// withMigrationLock runs fn while holding the migration advisory lock. The
+// lock is session-scoped, so it is taken on a dedicated connection that is
+// kept until fn returns.
+func withMigrationLock(ctx context.Context, db *sql.DB, fn func() error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	return fn()
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// ensureMigrationTable creates schema_migrations. It is only called with the
+// migration lock held, by the commands that change the schema.
+func ensureMigrationTable(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum VARCHAR(64) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// appliedMigrations reads schema_migrations without writing anything, so
+// checks and status reports are read-only. A database without the table has
+// nothing applied.
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[int]appliedMigration, error) {
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up schema_migrations: %w", err)
+	}
+	if !exists {
+		return map[int]appliedMigration{}, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.checksum, &a.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = a
+	}
+
+	return applied, rows.Err()
+}
+
+func verifyApplied(migrations []*Migration, applied map[int]appliedMigration) error {
+	known := make(map[int]*Migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+
+	for version, a := range applied {
+		m, ok := known[version]
+		if !ok {
+			return fmt.Errorf("migration %d is applied but not in this build", version)
+		}
+		if m.Checksum != a.checksum {
+			return fmt.Errorf("migration %d_%s was modified after it was applied", m.Version, m.Name)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m *Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+		m.Version, m.Name, m.Checksum,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+	}
+
+	return nil
+}
+
+func revertMigration(ctx context.Context, db *sql.DB, m *Migration) error {
+	if m.Down == "" {
+		return fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+		return fmt.Errorf("rollback of %d_%s failed: %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version); err != nil {
+		return fmt.Errorf("failed to record rollback of %d: %w", m.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit rollback of %d: %w", m.Version, err)
 	}
 
 	return nil
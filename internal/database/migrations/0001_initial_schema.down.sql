@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_interests;
+DROP TABLE IF EXISTS interest_groups;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS users;
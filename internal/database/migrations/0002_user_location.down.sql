@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_location;
+ALTER TABLE users DROP COLUMN IF EXISTS share_location;
+ALTER TABLE users DROP COLUMN IF EXISTS longitude;
+ALTER TABLE users DROP COLUMN IF EXISTS latitude;
+ALTER TABLE users DROP COLUMN IF EXISTS city;
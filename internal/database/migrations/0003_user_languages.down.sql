@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_languages;
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
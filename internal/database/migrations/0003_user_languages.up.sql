@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
+
+CREATE TABLE IF NOT EXISTS user_languages (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    language_code VARCHAR(8) NOT NULL,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('speaks', 'learning')),
+    level VARCHAR(2) NOT NULL CHECK (level IN ('A1', 'A2', 'B1', 'B2', 'C1', 'C2')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, kind, language_code)
+);
+CREATE INDEX IF NOT EXISTS idx_user_languages_lookup ON user_languages(kind, language_code, user_id);
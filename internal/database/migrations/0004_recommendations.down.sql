@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_recommendations;
+DROP INDEX IF EXISTS idx_user_interests_interest_id;
+ALTER TABLE users DROP COLUMN IF EXISTS last_active_at;
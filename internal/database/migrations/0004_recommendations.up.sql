@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_active_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_user_interests_interest_id ON user_interests(interest_id, user_id);
+
+CREATE TABLE IF NOT EXISTS user_recommendations (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recommended_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    shared_interests INTEGER NOT NULL,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, recommended_user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_recommendations_score ON user_recommendations(user_id, score DESC);
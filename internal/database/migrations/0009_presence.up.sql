@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS user_presence (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    instance_id VARCHAR(64) NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, instance_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_presence_instance ON user_presence(instance_id);
@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS realtime_event_seq;
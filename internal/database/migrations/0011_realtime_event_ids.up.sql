@@ -0,0 +1 @@
+CREATE SEQUENCE IF NOT EXISTS realtime_event_seq;
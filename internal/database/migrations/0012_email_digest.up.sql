@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_frequency VARCHAR(10) NOT NULL DEFAULT 'weekly';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_sent_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_user_interests_joined ON user_interests(interest_id, joined_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_interest_groups_name_trgm;
+DROP INDEX IF EXISTS idx_users_username_trgm;
+ALTER TABLE interest_groups DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS multilingual_tsvector(TEXT);
@@ -0,0 +1,21 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE OR REPLACE FUNCTION multilingual_tsvector(body TEXT) RETURNS tsvector
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT to_tsvector('english', body) || to_tsvector('spanish', body) ||
+           to_tsvector('french', body) || to_tsvector('german', body)
+$$;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', username || ' ' || COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')), 'A') ||
+    setweight(multilingual_tsvector(COALESCE(bio, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_users_search ON users USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops);
+
+ALTER TABLE interest_groups ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') ||
+    setweight(multilingual_tsvector(COALESCE(description, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_interest_groups_search ON interest_groups USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_interest_groups_name_trgm ON interest_groups USING GIN (name gin_trgm_ops);
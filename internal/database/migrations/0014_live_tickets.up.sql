@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS live_tickets (
+    ticket_hash CHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_live_tickets_expires_at ON live_tickets(expires_at);
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS utc_offset_minutes INTEGER;
+
+UPDATE users u
+SET utc_offset_minutes = EXTRACT(EPOCH FROM tz.utc_offset) / 60
+FROM pg_timezone_names tz
+WHERE tz.name = u.timezone;
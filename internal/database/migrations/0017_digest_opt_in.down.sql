@@ -0,0 +1 @@
+ALTER TABLE users ALTER COLUMN digest_frequency SET DEFAULT 'weekly';
@@ -0,0 +1,4 @@
+-- The digest was on by default; members now opt in. Nobody could tell the
+-- default from a choice of weekly, so everyone on weekly is switched off.
+ALTER TABLE users ALTER COLUMN digest_frequency SET DEFAULT 'off';
+UPDATE users SET digest_frequency = 'off' WHERE digest_frequency = 'weekly';
@@ -1,13 +1,11 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"windsurf-project/internal/models"
 	"windsurf-project/internal/service"
 	"windsurf-project/pkg/response"
-	"windsurf-project/pkg/validator"
 )
 
 type AuthHandler struct {
@@ -26,34 +24,21 @@ func NewAuthHandler(authService *service.AuthService, emailService *service.Emai
 // POST /api/auth/register
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req models.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "invalid request body")
-		return
-	}
-
-	// Validate input
-	if err := validator.ValidateEmail(req.Email); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
-		return
-	}
-	if err := validator.ValidateUsername(req.Username); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
-		return
-	}
-	if err := validator.ValidatePassword(req.Password); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Register user
-	authResp, err := h.authService.Register(&req)
+	authResp, err := h.authService.Register(r.Context(), &req)
 	if err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+		serviceError(w, r, err)
 		return
 	}
 
 	// Send welcome email (async, don't block on failure)
-	go h.emailService.SendWelcomeEmail(req.Email, req.Username)
+	h.emailService.SendAsync(func() error {
+		return h.emailService.SendWelcomeEmail(req.Email, req.Username)
+	})
 
 	response.Created(w, authResp)
 }
@@ -62,25 +47,14 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 // POST /api/auth/login
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req models.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "invalid request body")
-		return
-	}
-
-	// Validate input
-	if err := validator.ValidateEmail(req.Email); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
-		return
-	}
-	if err := validator.ValidateRequired("password", req.Password); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Login user
-	authResp, err := h.authService.Login(&req)
+	authResp, err := h.authService.Login(r.Context(), &req)
 	if err != nil {
-		response.Error(w, http.StatusUnauthorized, err.Error())
+		serviceError(w, r, err)
 		return
 	}
 
@@ -91,27 +65,22 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 // POST /api/auth/password-reset/request
 func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
 	var req models.PasswordResetRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "invalid request body")
-		return
-	}
-
-	// Validate input
-	if err := validator.ValidateEmail(req.Email); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Request password reset
-	token, err := h.authService.RequestPasswordReset(req.Email)
+	token, err := h.authService.RequestPasswordReset(r.Context(), req.Email)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, "failed to process password reset request")
+		serviceError(w, r, err)
 		return
 	}
 
 	// Send password reset email (async)
 	if token != "" {
-		go h.emailService.SendPasswordResetEmail(req.Email, token)
+		h.emailService.SendAsync(func() error {
+			return h.emailService.SendPasswordResetEmail(req.Email, token)
+		})
 	}
 
 	// Always return success to prevent email enumeration
@@ -124,24 +93,13 @@ func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Reques
 // POST /api/auth/password-reset/confirm
 func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	var req models.PasswordResetConfirm
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, "invalid request body")
-		return
-	}
-
-	// Validate input
-	if err := validator.ValidateRequired("token", req.Token); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
-		return
-	}
-	if err := validator.ValidatePassword(req.NewPassword); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Reset password
-	if err := h.authService.ResetPassword(&req); err != nil {
-		response.Error(w, http.StatusBadRequest, err.Error())
+	if err := h.authService.ResetPassword(r.Context(), &req); err != nil {
+		serviceError(w, r, err)
 		return
 	}
 
@@ -156,7 +114,7 @@ func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	// User claims are set by the auth middleware
 	claims := r.Context().Value("user")
 	if claims == nil {
-		response.Error(w, http.StatusUnauthorized, "unauthorized")
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type BlockHandler struct {
+	blockService *service.BlockService
+}
+
+func NewBlockHandler(blockService *service.BlockService) *BlockHandler {
+	return &BlockHandler{blockService: blockService}
+}
+
+// Block blocks a user
+// POST /api/users/{id}/block
+func (h *BlockHandler) Block(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.blockService.Block(r.Context(), userID, targetID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "User blocked"})
+}
+
+// Unblock removes a block
+// DELETE /api/users/{id}/block
+func (h *BlockHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.blockService.Unblock(r.Context(), userID, targetID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "User unblocked"})
+}
+
+// ListBlocked returns the current user's block list
+// GET /api/users/me/blocks
+func (h *BlockHandler) ListBlocked(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	users, err := h.blockService.ListBlocked(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, users)
+}
+
+// Mute mutes a user
+// POST /api/users/{id}/mute
+func (h *BlockHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.blockService.Mute(r.Context(), userID, targetID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "User muted"})
+}
+
+// Unmute removes a mute
+// DELETE /api/users/{id}/mute
+func (h *BlockHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.blockService.Unmute(r.Context(), userID, targetID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "User unmuted"})
+}
+
+// ListMuted returns the current user's mute list
+// GET /api/users/me/mutes
+func (h *BlockHandler) ListMuted(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	users, err := h.blockService.ListMuted(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, users)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"windsurf-project/pkg/response"
+	"windsurf-project/pkg/validator"
+)
+
+// decodeJSON decodes the request body into dst and checks its validate
+// tags. On failure it writes the response and returns false: 400 for a
+// malformed body, and 400 listing every invalid field for a body that fails
+// validation.
+func decodeJSON[T any](w http.ResponseWriter, r *http.Request, dst *T) bool {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		response.Error(w, r, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+
+	if err := validator.Struct(dst); err != nil {
+		validationFailed(w, r, err)
+		return false
+	}
+	return true
+}
+
+// validationFailed writes the field errors of a failed validator.Struct.
+func validationFailed(w http.ResponseWriter, r *http.Request, err error) {
+	var fieldErrs validator.Errors
+	if !errors.As(err, &fieldErrs) {
+		serviceError(w, r, err)
+		return
+	}
+
+	problem := &response.Problem{
+		Status: http.StatusBadRequest,
+		Code:   "validation_failed",
+		Detail: fieldErrs.Error(),
+	}
+	for _, fe := range fieldErrs {
+		problem.Errors = append(problem.Errors, response.FieldError{Field: fe.Field, Code: fe.Rule, Message: fe.Message})
+	}
+	response.WriteProblem(w, r, problem)
+}
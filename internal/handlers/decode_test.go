@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/pkg/response"
+)
+
+func TestDecodeJSONReportsEveryInvalidField(t *testing.T) {
+	body := `{"email": "nope", "username": "ab", "password": ""}`
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	var req models.RegisterRequest
+	if decodeJSON(w, r, &req) {
+		t.Fatal("decodeJSON accepted an invalid body")
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+
+	var env response.Response
+	if err := json.NewDecoder(w.Body).Decode(&env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Code != "validation_failed" {
+		t.Errorf("code = %q, want validation_failed", env.Code)
+	}
+	var fields []string
+	for _, fe := range env.Errors {
+		fields = append(fields, fe.Field)
+	}
+	if got := strings.Join(fields, ","); got != "email,username,password" {
+		t.Errorf("fields = %s, want email,username,password", got)
+	}
+}
+
+func TestDecodeJSONRejectsMalformedBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/login", strings.NewReader("{"))
+	w := httptest.NewRecorder()
+
+	var req models.LoginRequest
+	if decodeJSON(w, r, &req) || w.Code != http.StatusBadRequest {
+		t.Errorf("decodeJSON accepted a malformed body, status %d", w.Code)
+	}
+}
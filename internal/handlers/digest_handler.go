@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type DigestHandler struct {
+	digestService *service.DigestService
+}
+
+func NewDigestHandler(digestService *service.DigestService) *DigestHandler {
+	return &DigestHandler{digestService: digestService}
+}
+
+// GetSettings returns how often the current user receives the email digest
+// GET /api/users/me/digest
+func (h *DigestHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	settings, err := h.digestService.GetSettings(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, settings)
+}
+
+// UpdateSettings changes how often the current user receives the email digest
+// PUT /api/users/me/digest
+func (h *DigestHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.DigestSettings
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.digestService.UpdateSettings(r.Context(), userID, &req); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, req)
+}
+
+// unsubscribePage is shown by both unsubscribe endpoints. Done is false on
+// the confirmation page, whose form posts the token back.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Email digest</title></head>
+<body>
+{{if .Done}}<p>You have been unsubscribed from the email digest.</p>
+{{else}}<p>Stop receiving the email digest?</p>
+<form method="post" action="?token={{.Token}}"><button type="submit">Unsubscribe</button></form>
+{{end}}</body>
+</html>
+`))
+
+// ConfirmUnsubscribe shows a page asking to confirm the unsubscribe link in
+// the email. It changes nothing, so mail scanners and link prefetchers that
+// follow the link do not unsubscribe anyone.
+// GET /api/digest/unsubscribe?token=
+func (h *DigestHandler) ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if err := h.digestService.CheckUnsubscribeToken(token); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	renderUnsubscribePage(w, token, false)
+}
+
+// Unsubscribe turns the digest off. It is posted by the confirmation page and
+// by mail clients for one-click unsubscribe (RFC 8058).
+// POST /api/digest/unsubscribe?token=
+func (h *DigestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := h.digestService.Unsubscribe(r.Context(), r.URL.Query().Get("token")); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	renderUnsubscribePage(w, "", true)
+}
+
+func renderUnsubscribePage(w http.ResponseWriter, token string, done bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := unsubscribePage.Execute(w, struct {
+		Token string
+		Done  bool
+	}{token, done}); err != nil {
+		log.Printf("digest: failed to render unsubscribe page: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"windsurf-project/internal/repository"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+// StatusClientClosedRequest is the non-standard status recorded when the
+// client goes away before the response is ready. Nobody receives it, but it
+// keeps cancelled requests out of the 5xx counts in the access log.
+const StatusClientClosedRequest = 499
+
+// serviceError writes the response for an error returned by a service call.
+// The status and code follow from the error's kind; internal failures are
+// logged and reported without their message, which may describe the
+// database.
+func serviceError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(r.Context().Err(), context.Canceled) {
+		response.WriteProblem(w, r, &response.Problem{
+			Status: StatusClientClosedRequest,
+			Title:  "Client Closed Request",
+			Code:   "client_closed_request",
+			Detail: "request cancelled",
+		})
+		return
+	}
+
+	status := errorStatus(err)
+	problem := &response.Problem{
+		Status: status,
+		Code:   errorCode(err, status),
+		Detail: service.Message(err),
+	}
+	switch {
+	case status == http.StatusGatewayTimeout:
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		problem.Detail = "request timed out"
+	case status >= 500:
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		problem.Detail = "internal server error"
+	case problem.Detail == "":
+		problem.Detail = http.StatusText(status)
+	}
+	if field := service.Field(err); field != "" {
+		problem.Errors = []response.FieldError{{Field: field, Code: problem.Code, Message: problem.Detail}}
+	}
+
+	response.WriteProblem(w, r, problem)
+}
+
+// errorStatus maps an error kind to its HTTP status.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrConflict), repository.IsUniqueViolation(err):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case repository.IsTimeout(err):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorCode returns the stable code for err: the service's own code when it
+// set one, otherwise one per kind.
+func errorCode(err error, status int) string {
+	if code := service.Code(err); code != "" {
+		return code
+	}
+	if errors.Is(err, service.ErrValidation) {
+		return "validation_failed"
+	}
+	return response.CodeForStatus(status)
+}
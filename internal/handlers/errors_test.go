@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"windsurf-project/internal/repository"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"validation", service.Validation("q is required"), http.StatusBadRequest},
+		{"unauthorized", service.Unauthorized("invalid token"), http.StatusUnauthorized},
+		{"forbidden", service.Forbidden("this profile is private"), http.StatusForbidden},
+		{"repository not found", fmt.Errorf("user %w", repository.ErrNotFound), http.StatusNotFound},
+		{"wrapped conflict", fmt.Errorf("failed to create user: %w", fmt.Errorf("username %w", repository.ErrConflict)), http.StatusConflict},
+		{"raw unique violation", fmt.Errorf("failed to save: %w", &pq.Error{Code: "23505"}), http.StatusConflict},
+		{"rate limited", service.ErrFirstContactLimit, http.StatusTooManyRequests},
+		{"timeout", fmt.Errorf("failed to search: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"internal", fmt.Errorf("failed to get user: %w", &pq.Error{Code: "42P01"}), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := errorStatus(tt.err); got != tt.want {
+			t.Errorf("%s: errorStatus(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestMessageHidesInternalErrors(t *testing.T) {
+	wrapped := fmt.Errorf("failed to create user: %w", fmt.Errorf("username %w", repository.ErrConflict))
+	if got := service.Message(wrapped); got != "username already exists" {
+		t.Errorf("Message(%v) = %q, want %q", wrapped, got, "username already exists")
+	}
+
+	internal := fmt.Errorf("failed to get user: %w", &pq.Error{Code: "42P01", Message: `relation "users" does not exist`})
+	if got := service.Message(internal); got != "" {
+		t.Errorf("Message of an internal error = %q, want empty", got)
+	}
+}
+
+func TestServiceErrorNegotiatesProblemJSON(t *testing.T) {
+	err := fmt.Errorf("failed to register: %w", &service.Error{
+		Kind:    service.ErrConflict,
+		Code:    "email_taken",
+		Field:   "email",
+		Message: "user with this email already exists",
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/register", nil)
+	r.Header.Set("Accept", "application/problem+json")
+	w := httptest.NewRecorder()
+	serviceError(w, r, err)
+
+	if ct := w.Header().Get("Content-Type"); ct != response.ProblemContentType {
+		t.Fatalf("Content-Type = %q, want %q", ct, response.ProblemContentType)
+	}
+	var p response.Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusConflict || p.Code != "email_taken" || p.Instance != "/api/auth/register" {
+		t.Errorf("problem = %+v", p)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Field != "email" {
+		t.Errorf("field errors = %+v, want one for email", p.Errors)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/api/auth/register", nil)
+	w = httptest.NewRecorder()
+	serviceError(w, r, err)
+
+	var env response.Response
+	if err := json.NewDecoder(w.Body).Decode(&env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Success || env.Error != "user with this email already exists" || env.Code != "email_taken" {
+		t.Errorf("envelope = %+v", env)
+	}
+}
+
+func TestServiceErrorHidesInternalDetail(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+	r.Header.Set("Accept", "application/problem+json")
+	w := httptest.NewRecorder()
+	serviceError(w, r, fmt.Errorf("failed to get user: %w", &pq.Error{Code: "42P01"}))
+
+	var p response.Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusInternalServerError || p.Code != "internal_error" || p.Detail != "internal server error" {
+		t.Errorf("problem = %+v", p)
+	}
+}
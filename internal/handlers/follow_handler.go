@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type FollowHandler struct {
+	followService *service.FollowService
+}
+
+func NewFollowHandler(followService *service.FollowService) *FollowHandler {
+	return &FollowHandler{followService: followService}
+}
+
+// GetProfile returns another member's public profile with connection counts
+// GET /api/users/{id}
+func (h *FollowHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	viewerID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.followService.GetProfile(r.Context(), viewerID, targetID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, profile)
+}
+
+// Follow follows a user, or sends a request if their profile is private
+// POST /api/users/{id}/follow
+func (h *FollowHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.followService.Follow(r.Context(), userID, targetID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, status)
+}
+
+// Unfollow stops following a user or withdraws a pending request
+// DELETE /api/users/{id}/follow
+func (h *FollowHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	userID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.followService.Unfollow(r.Context(), userID, targetID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Unfollowed"})
+}
+
+// Followers lists a user's followers
+// GET /api/users/{id}/followers?cursor=&limit=20
+func (h *FollowHandler) Followers(w http.ResponseWriter, r *http.Request) {
+	viewerID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := pagination(r)
+	page, err := h.followService.ListFollowers(r.Context(), viewerID, targetID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, page)
+}
+
+// Following lists the users a user follows
+// GET /api/users/{id}/following?cursor=&limit=20
+func (h *FollowHandler) Following(w http.ResponseWriter, r *http.Request) {
+	viewerID, targetID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := pagination(r)
+	page, err := h.followService.ListFollowing(r.Context(), viewerID, targetID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, page)
+}
+
+// Requests lists pending follow requests sent to the current user
+// GET /api/users/me/follow-requests?cursor=&limit=20
+func (h *FollowHandler) Requests(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	limit, _ := pagination(r)
+	page, err := h.followService.ListRequests(r.Context(), userID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, page)
+}
+
+// AcceptRequest accepts a pending follow request
+// POST /api/users/me/follow-requests/{id}/accept
+func (h *FollowHandler) AcceptRequest(w http.ResponseWriter, r *http.Request) {
+	userID, followerID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.followService.AcceptRequest(r.Context(), userID, followerID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Follow request accepted"})
+}
+
+// RejectRequest declines a pending follow request
+// DELETE /api/users/me/follow-requests/{id}
+func (h *FollowHandler) RejectRequest(w http.ResponseWriter, r *http.Request) {
+	userID, followerID, ok := userAndTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.followService.RejectRequest(r.Context(), userID, followerID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Follow request rejected"})
+}
+
+// UpdatePrivacy makes the current user's profile private or public
+// PUT /api/users/me/privacy
+func (h *FollowHandler) UpdatePrivacy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.UpdatePrivacyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.followService.UpdatePrivacy(r.Context(), userID, req.IsPrivate); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, req)
+}
+
+// userAndTarget reads the authenticated user and the {id} path variable,
+// writing an error response when either is missing.
+func userAndTarget(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return 0, 0, false
+	}
+
+	targetID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "invalid user id")
+		return 0, 0, false
+	}
+
+	return userID, targetID, true
+}
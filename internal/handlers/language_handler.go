@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type LanguageHandler struct {
+	languageService *service.LanguageService
+}
+
+func NewLanguageHandler(languageService *service.LanguageService) *LanguageHandler {
+	return &LanguageHandler{languageService: languageService}
+}
+
+// GetProfile returns the languages the current user speaks and is learning
+// GET /api/users/me/languages
+func (h *LanguageHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	profile, err := h.languageService.GetProfile(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, profile)
+}
+
+// UpdateProfile replaces the current user's declared languages
+// PUT /api/users/me/languages
+func (h *LanguageHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.LanguageProfile
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	profile, err := h.languageService.UpdateProfile(r.Context(), userID, &req)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, profile)
+}
+
+// Partners lists reciprocal tandem partners, best match first
+// GET /api/languages/partners?limit=20&offset=0
+func (h *LanguageHandler) Partners(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	limit, offset := pagination(r)
+	partners, err := h.languageService.FindPartners(r.Context(), userID, limit, offset)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, partners)
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type MessageHandler struct {
+	messageService *service.MessageService
+}
+
+func NewMessageHandler(messageService *service.MessageService) *MessageHandler {
+	return &MessageHandler{messageService: messageService}
+}
+
+// SendToUser sends a direct message, starting a conversation if needed
+// POST /api/messages
+func (h *MessageHandler) SendToUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.SendMessageRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	msg, err := h.messageService.SendToUser(r.Context(), userID, req.RecipientID, req.Body)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Created(w, msg)
+}
+
+// Send posts a message to an existing conversation
+// POST /api/conversations/{id}/messages
+func (h *MessageHandler) Send(w http.ResponseWriter, r *http.Request) {
+	userID, conversationID, ok := userAndConversation(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SendMessageRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	msg, err := h.messageService.Send(r.Context(), userID, conversationID, req.Body)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Created(w, msg)
+}
+
+// ListConversations lists the current user's conversations
+// GET /api/conversations?cursor=&limit=20
+func (h *MessageHandler) ListConversations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	limit, _ := pagination(r)
+	page, err := h.messageService.ListConversations(r.Context(), userID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, page)
+}
+
+// ListMessages lists messages in a conversation, newest first
+// GET /api/conversations/{id}/messages?cursor=&limit=20
+func (h *MessageHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	userID, conversationID, ok := userAndConversation(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := pagination(r)
+	page, err := h.messageService.ListMessages(r.Context(), userID, conversationID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, page)
+}
+
+// MarkRead marks a conversation as read up to a message, or entirely
+// POST /api/conversations/{id}/read
+func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, conversationID, ok := userAndConversation(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.MarkReadRequest
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	if err := h.messageService.MarkRead(r.Context(), userID, conversationID, req.MessageID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Conversation marked as read"})
+}
+
+// UnreadCount returns the number of unread direct messages
+// GET /api/messages/unread
+func (h *MessageHandler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	count, err := h.messageService.UnreadCount(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]int{"unread": count})
+}
+
+// GetSettings returns who may message the current user
+// GET /api/users/me/messaging
+func (h *MessageHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	settings, err := h.messageService.GetSettings(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, settings)
+}
+
+// UpdateSettings changes who may message the current user
+// PUT /api/users/me/messaging
+func (h *MessageHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.MessagingSettings
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.messageService.UpdateSettings(r.Context(), userID, &req); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, req)
+}
+
+func userAndConversation(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return 0, 0, false
+	}
+
+	conversationID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "invalid conversation id")
+		return 0, 0, false
+	}
+
+	return userID, conversationID, true
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+// CreateReport reports a member for moderation
+// POST /api/reports
+func (h *ModerationHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.CreateReportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	report, err := h.moderationService.CreateReport(r.Context(), userID, &req)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Created(w, report)
+}
+
+// ListReports returns the moderation queue
+// GET /api/admin/reports?status=open&limit=20&offset=0
+func (h *ModerationHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pagination(r)
+	reports, err := h.moderationService.ListReports(r.Context(), r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, reports)
+}
+
+// GetReport returns a single report
+// GET /api/admin/reports/{id}
+func (h *ModerationHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := reportID(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.moderationService.GetReport(r.Context(), id)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, report)
+}
+
+// AssignReport assigns a report to a moderator
+// POST /api/admin/reports/{id}/assign
+func (h *ModerationHandler) AssignReport(w http.ResponseWriter, r *http.Request) {
+	moderatorID, _ := middleware.UserID(r)
+	id, ok := reportID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AssignReportRequest
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	report, err := h.moderationService.AssignReport(r.Context(), id, moderatorID, &req)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, report)
+}
+
+// ResolveReport closes a report
+// POST /api/admin/reports/{id}/resolve
+func (h *ModerationHandler) ResolveReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := reportID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ResolveReportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	report, err := h.moderationService.ResolveReport(r.Context(), id, &req)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, report)
+}
+
+// TakeAction warns, suspends or removes content of the reported member
+// POST /api/admin/reports/{id}/actions
+func (h *ModerationHandler) TakeAction(w http.ResponseWriter, r *http.Request) {
+	moderatorID, _ := middleware.UserID(r)
+	id, ok := reportID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ModerationActionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	action, err := h.moderationService.TakeAction(r.Context(), id, moderatorID, &req)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Created(w, action)
+}
+
+func reportID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "invalid report id")
+		return 0, false
+	}
+	return id, true
+}
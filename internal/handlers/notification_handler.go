@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// List returns the current user's notifications, newest first
+// GET /api/notifications?cursor=&limit=20&unread=true
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	limit, _ := pagination(r)
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+	page, err := h.notificationService.List(r.Context(), userID, r.URL.Query().Get("cursor"), unreadOnly, limit)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, page)
+}
+
+// UnreadCount returns the number of unread notifications
+// GET /api/notifications/unread-count
+func (h *NotificationHandler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	count, err := h.notificationService.UnreadCount(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]int{"unread_count": count})
+}
+
+// MarkRead marks one notification as read
+// POST /api/notifications/{id}/read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, "invalid notification id")
+		return
+	}
+
+	if err := h.notificationService.MarkRead(r.Context(), userID, id); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "Notification marked as read"})
+}
+
+// MarkAllRead marks every notification as read
+// POST /api/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.notificationService.MarkAllRead(r.Context(), userID); err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, map[string]string{"message": "All notifications marked as read"})
+}
+
+// GetPreferences returns per-type notification preferences
+// GET /api/notifications/preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	prefs, err := h.notificationService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, prefs)
+}
+
+// UpdatePreferences changes per-type notification preferences
+// PUT /api/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req []models.NotificationPreference
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(r.Context(), userID, req)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, prefs)
+}
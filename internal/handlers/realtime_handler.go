@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/realtime"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type RealtimeHandler struct {
+	authService    *service.AuthService
+	ticketService  *service.LiveTicketService
+	messageService *service.MessageService
+	hub            *realtime.Hub
+	upgrader       websocket.Upgrader
+}
+
+func NewRealtimeHandler(authService *service.AuthService, ticketService *service.LiveTicketService, messageService *service.MessageService, hub *realtime.Hub) *RealtimeHandler {
+	h := &RealtimeHandler{
+		authService:    authService,
+		ticketService:  ticketService,
+		messageService: messageService,
+		hub:            hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Connections are authenticated by token, not cookies, so any
+			// origin is accepted, matching the CORS policy.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+
+	hub.Handle("typing", h.typing)
+	return h
+}
+
+// Ticket issues a one-time ticket for opening a WebSocket or SSE connection
+// from a browser.
+// POST /api/auth/live-ticket
+func (h *RealtimeHandler) Ticket(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	ticket, err := h.ticketService.Issue(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Created(w, ticket)
+}
+
+// Serve upgrades to a WebSocket for live messages, typing and presence. The
+// caller is authenticated by the Authorization header or a ticket.
+// GET /api/ws
+func (h *RealtimeHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticateLive(w, r, h.authService, h.ticketService)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an error response.
+		log.Printf("realtime: upgrade failed: %v", err)
+		return
+	}
+
+	h.hub.Serve(conn, userID)
+}
+
+// authenticateLive resolves the caller of a long-lived connection from the
+// Authorization header or, for browser APIs such as WebSocket and
+// EventSource that cannot set headers, a one-time ticket in the ticket query
+// parameter. JWTs are not accepted in the query string, where they would end
+// up in access logs and browser history.
+func authenticateLive(w http.ResponseWriter, r *http.Request, authService *service.AuthService, ticketService *service.LiveTicketService) (int, bool) {
+	if token, ok := middleware.BearerToken(r); ok {
+		claims, err := authService.ValidateToken(r.Context(), token)
+		if err != nil {
+			serviceError(w, r, err)
+			return 0, false
+		}
+
+		userID, ok := middleware.UserID(middleware.WithClaims(r, claims))
+		if !ok {
+			response.Error(w, r, http.StatusUnauthorized, "invalid or expired token")
+			return 0, false
+		}
+		return userID, true
+	}
+
+	ticket := r.URL.Query().Get("ticket")
+	if ticket == "" {
+		response.Error(w, r, http.StatusUnauthorized, "missing authorization token or ticket")
+		return 0, false
+	}
+
+	userID, err := ticketService.Redeem(r.Context(), ticket)
+	if err != nil {
+		serviceError(w, r, err)
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *RealtimeHandler) typing(userID int, data json.RawMessage) (*realtime.Event, error) {
+	var req struct {
+		ConversationID int `json:"conversation_id"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil || req.ConversationID == 0 {
+		return nil, service.InvalidField("conversation_id", "conversation_id is required")
+	}
+
+	if err := h.messageService.Typing(context.Background(), userID, req.ConversationID); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
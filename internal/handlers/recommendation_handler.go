@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type RecommendationHandler struct {
+	recService *service.RecommendationService
+}
+
+func NewRecommendationHandler(recService *service.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{recService: recService}
+}
+
+// List returns people the current user may want to meet
+// GET /api/users/recommendations?limit=20&offset=0
+func (h *RecommendationHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	limit, offset := pagination(r)
+	recs, err := h.recService.List(r.Context(), userID, limit, offset)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, recs)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// Search runs a full-text search over members and interest groups
+// GET /api/search?q=&lang=en&type=member&limit=20&offset=0
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	limit, offset := pagination(r)
+	results, err := h.searchService.Search(r.Context(), userID, query.Get("q"), query.Get("lang"), query.Get("type"), limit, offset)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, results)
+}
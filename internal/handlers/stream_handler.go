@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"windsurf-project/internal/realtime"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+// keepAliveInterval is short enough to stop proxies and load balancers from
+// closing an idle stream.
+const keepAliveInterval = 25 * time.Second
+
+type StreamHandler struct {
+	authService         *service.AuthService
+	ticketService       *service.LiveTicketService
+	notificationService *service.NotificationService
+	messageService      *service.MessageService
+	hub                 *realtime.Hub
+}
+
+func NewStreamHandler(authService *service.AuthService, ticketService *service.LiveTicketService, notificationService *service.NotificationService, messageService *service.MessageService, hub *realtime.Hub) *StreamHandler {
+	return &StreamHandler{
+		authService:         authService,
+		ticketService:       ticketService,
+		notificationService: notificationService,
+		messageService:      messageService,
+		hub:                 hub,
+	}
+}
+
+// Stream pushes live events to clients that cannot use WebSockets, as
+// Server-Sent Events. Clients resume after a reconnect with the Last-Event-ID
+// header (or the last_event_id query parameter); if the missed events are no
+// longer buffered a "reset" event tells the client to refetch.
+// GET /api/stream
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticateLive(w, r, h.authService, h.ticketService)
+	if !ok {
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var lastID int64
+	resume := lastEventID != ""
+	if resume {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || id < 0 {
+			response.Error(w, r, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+		lastID = id
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		response.Error(w, r, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	// The stream outlives the server's write timeout by design.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("stream: cannot clear write deadline: %v", err)
+	}
+
+	sub, missed, complete := h.hub.Subscribe(userID, lastID, resume)
+	defer h.hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if !complete {
+		writeStreamEvent(w, realtime.Frame{Type: "reset", Data: []byte(`{"type":"reset"}`)})
+	}
+	h.writeCounters(r.Context(), w, userID)
+
+	// Frames published between subscribing and reading the replay buffer
+	// arrive on both paths, so replayed IDs are skipped on the live path.
+	replayed := make(map[int64]struct{}, len(missed))
+	for _, frame := range missed {
+		writeStreamEvent(w, frame)
+		replayed[frame.ID] = struct{}{}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Done():
+			return
+		case frame := <-sub.Frames():
+			if _, ok := replayed[frame.ID]; ok {
+				continue
+			}
+			writeStreamEvent(w, frame)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeCounters sends the current unread counters so a (re)connecting client
+// starts from the right badge values. They carry no ID, leaving the client's
+// resume position unchanged.
+func (h *StreamHandler) writeCounters(ctx context.Context, w http.ResponseWriter, userID int) {
+	if count, err := h.notificationService.UnreadCount(ctx, userID); err == nil {
+		writeCounter(w, "notification.unread", count)
+	} else {
+		log.Printf("stream: failed to count notifications: %v", err)
+	}
+	if count, err := h.messageService.UnreadCount(ctx, userID); err == nil {
+		writeCounter(w, "messages.unread", count)
+	} else {
+		log.Printf("stream: failed to count messages: %v", err)
+	}
+}
+
+func writeCounter(w http.ResponseWriter, eventType string, count int) {
+	data, err := json.Marshal(realtime.Event{Type: eventType, Data: map[string]int{"unread_count": count}})
+	if err != nil {
+		return
+	}
+	writeStreamEvent(w, realtime.Frame{Type: eventType, Data: data})
+}
+
+func writeStreamEvent(w http.ResponseWriter, frame realtime.Frame) {
+	if frame.ID > 0 {
+		fmt.Fprintf(w, "id: %d\n", frame.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", frame.Type, frame.Data)
+}
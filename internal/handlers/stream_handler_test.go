@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamRejectsTokenInQuery(t *testing.T) {
+	h := &StreamHandler{}
+	r := httptest.NewRequest(http.MethodGet, "/api/stream?token=eyJhbGciOiJIUzI1NiJ9.e30.sig", nil)
+	w := httptest.NewRecorder()
+
+	h.Stream(w, r)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401 for a JWT in the query string", w.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"windsurf-project/internal/middleware"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/service"
+	"windsurf-project/pkg/response"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type UserHandler struct {
+	userService *service.UserService
+}
+
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// GetLocation returns the current user's stored location
+// GET /api/users/me/location
+func (h *UserHandler) GetLocation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	loc, err := h.userService.GetLocation(r.Context(), userID)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, loc)
+}
+
+// UpdateLocation sets the current user's opt-in city and coarse coordinates
+// PUT /api/users/me/location
+func (h *UserHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req models.UpdateLocationRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	loc, err := h.userService.UpdateLocation(r.Context(), userID, &req)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, loc)
+}
+
+// Nearby lists members within a radius of the current user, closest first
+// GET /api/users/nearby?km=10&limit=20&offset=0
+func (h *UserHandler) Nearby(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserID(r)
+	if !ok {
+		response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	radius, err := strconv.ParseFloat(r.URL.Query().Get("km"), 64)
+	if err != nil {
+		radius = 10
+	}
+	limit, offset := pagination(r)
+
+	users, err := h.userService.FindNearby(r.Context(), userID, radius, limit, offset)
+	if err != nil {
+		serviceError(w, r, err)
+		return
+	}
+
+	response.Success(w, users)
+}
+
+// pagination reads limit and offset query parameters, clamping the limit.
+func pagination(r *http.Request) (int, int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
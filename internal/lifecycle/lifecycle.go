@@ -0,0 +1,85 @@
+// Package lifecycle tracks background work and shutdown hooks so the server
+// can stop without cutting off in-flight emails, jobs or connections.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+)
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Registry collects shutdown hooks and background goroutines.
+type Registry struct {
+	mu    sync.Mutex
+	hooks []hook
+	wg    sync.WaitGroup
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// OnShutdown registers fn to run during Shutdown. Hooks run in reverse
+// registration order, so later components stop before the ones they use.
+func (r *Registry) OnShutdown(name string, fn func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook{name: name, fn: fn})
+}
+
+// Go runs fn in a goroutine that Shutdown waits for.
+func (r *Registry) Go(fn func()) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		fn()
+	}()
+}
+
+// Shutdown runs every hook, then waits for the goroutines started with Go.
+// It returns early with an error if ctx expires first.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	hooks := make([]hook, len(r.hooks))
+	copy(hooks, r.hooks)
+	r.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			log.Printf("shutdown: %s: %v", hooks[i].name, err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background work did not finish: %w", ctx.Err())
+	}
+}
+
+// StopContext returns a context that is cancelled when stop is closed, so
+// background jobs abandon in-flight queries on shutdown.
+func StopContext(stop <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
@@ -2,9 +2,13 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/golang-jwt/jwt/v5"
+
 	"windsurf-project/internal/service"
 	"windsurf-project/pkg/response"
 )
@@ -18,25 +22,83 @@ func Auth(authService *service.AuthService) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				response.Error(w, http.StatusUnauthorized, "missing authorization header")
+				response.Error(w, r, http.StatusUnauthorized, "missing authorization header")
 				return
 			}
 
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				response.Error(w, http.StatusUnauthorized, "invalid authorization header format")
+			token, ok := BearerToken(r)
+			if !ok {
+				response.Error(w, r, http.StatusUnauthorized, "invalid authorization header format")
 				return
 			}
 
-			token := parts[1]
-			claims, err := authService.ValidateToken(token)
+			claims, err := authService.ValidateToken(r.Context(), token)
+			if errors.Is(err, service.ErrUnauthorized) || errors.Is(err, service.ErrForbidden) {
+				response.Error(w, r, http.StatusUnauthorized, "invalid or expired token")
+				return
+			}
 			if err != nil {
-				response.Error(w, http.StatusUnauthorized, "invalid or expired token")
+				log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+				response.Error(w, r, http.StatusInternalServerError, "internal server error")
+				return
+			}
+
+			next.ServeHTTP(w, WithClaims(r, claims))
+		})
+	}
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>"
+// header.
+func BearerToken(r *http.Request) (string, bool) {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// WithClaims stores validated token claims in the request context, as Auth
+// does.
+func WithClaims(r *http.Request, claims *jwt.MapClaims) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), UserContextKey, claims))
+}
+
+// UserID returns the authenticated user's ID from the request context.
+func UserID(r *http.Request) (int, bool) {
+	claims, ok := r.Context().Value(UserContextKey).(*jwt.MapClaims)
+	if !ok || claims == nil {
+		return 0, false
+	}
+	id, ok := (*claims)["user_id"].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int(id), true
+}
+
+// Admin rejects authenticated users who are not administrators. It must be
+// installed after Auth.
+func Admin(authService *service.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := UserID(r)
+			if !ok {
+				response.Error(w, r, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+
+			isAdmin, err := authService.IsAdmin(r.Context(), userID)
+			if err != nil {
+				response.Error(w, r, http.StatusInternalServerError, "failed to check permissions")
+				return
+			}
+			if !isAdmin {
+				response.Error(w, r, http.StatusForbidden, "admin access required")
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), UserContextKey, claims)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r)
 		})
 	}
 }
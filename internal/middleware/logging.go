@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"bufio"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"time"
 )
@@ -16,6 +19,31 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Hijack lets WebSocket upgrades take over the connection through the
+// logging wrapper.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	rw.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Flush lets streaming responses such as Server-Sent Events push each event
+// through the logging wrapper.
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, which
+// streaming handlers use to lift the server's write deadline.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -24,11 +52,13 @@ func Logging(next http.Handler) http.Handler {
 		
 		next.ServeHTTP(rw, r)
 		
+		// Log the path only: query strings carry secrets such as live
+		// tickets and unsubscribe tokens.
 		duration := time.Since(start)
 		log.Printf(
 			"%s %s %d %s",
 			r.Method,
-			r.RequestURI,
+			r.URL.Path,
 			rw.status,
 			duration,
 		)
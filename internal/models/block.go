@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// BlockedUser is an entry in a user's block or mute list.
+type BlockedUser struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	AvatarURL *string   `json:"avatar_url,omitempty"`
+	Since     time.Time `json:"since"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+	DigestOff    = "off"
+)
+
+type DigestSettings struct {
+	Frequency string `json:"frequency" validate:"required"`
+}
+
+// DigestRecipient is a user whose digest is due, with the start of the
+// period it covers.
+type DigestRecipient struct {
+	ID        int
+	Email     string
+	Username  string
+	Frequency string
+	Since     time.Time
+	// PreviousSentAt is when the last digest went out, if ever, so a claim
+	// can be released when sending fails.
+	PreviousSentAt *time.Time
+}
+
+// Digest is the personalized summary emailed to one user.
+type Digest struct {
+	Username  string
+	Frequency string
+	Since     time.Time
+	Groups    []*DigestGroup
+}
+
+// DigestGroup lists the members who joined one of the recipient's interest
+// groups during the digest period.
+type DigestGroup struct {
+	InterestID     int
+	Name           string
+	NewMemberCount int
+	NewMembers     []*DigestMember
+}
+
+type DigestMember struct {
+	ID        int
+	Username  string
+	FirstName *string
+}
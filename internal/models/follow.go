@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+const (
+	FollowPending  = "pending"
+	FollowAccepted = "accepted"
+)
+
+// Relationships of the viewer to a profile.
+const (
+	RelationshipSelf      = "self"
+	RelationshipNone      = "none"
+	RelationshipPending   = "pending"
+	RelationshipFollowing = "following"
+	RelationshipFriends   = "friends"
+)
+
+// Connection is a user in a follower, following or follow-request list.
+type Connection struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	FirstName *string   `json:"first_name,omitempty"`
+	LastName  *string   `json:"last_name,omitempty"`
+	AvatarURL *string   `json:"avatar_url,omitempty"`
+	Since     time.Time `json:"since"`
+}
+
+// ConnectionPage is one cursor-paginated page of connections.
+type ConnectionPage struct {
+	Items      []*Connection `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// FollowStatus describes the relationship from the caller to another user.
+type FollowStatus struct {
+	Status string `json:"status"`
+}
+
+// PublicProfile is what other members can see about a user.
+type PublicProfile struct {
+	ID             int     `json:"id"`
+	Username       string  `json:"username"`
+	FirstName      *string `json:"first_name,omitempty"`
+	LastName       *string `json:"last_name,omitempty"`
+	Bio            *string `json:"bio,omitempty"`
+	AvatarURL      *string `json:"avatar_url,omitempty"`
+	City           *string `json:"city,omitempty"`
+	IsPrivate      bool    `json:"is_private"`
+	FollowerCount  int     `json:"follower_count"`
+	FollowingCount int     `json:"following_count"`
+	FriendCount    int     `json:"friend_count"`
+	// ViewerRelationship is how the viewer is connected to this user: one of
+	// the Relationship constants.
+	ViewerRelationship string    `json:"viewer_relationship"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+type UpdatePrivacyRequest struct {
+	IsPrivate bool `json:"is_private"`
+}
@@ -0,0 +1,32 @@
+package models
+
+const (
+	LanguageSpeaks   = "speaks"
+	LanguageLearning = "learning"
+)
+
+// UserLanguage is a language a user speaks or wants to learn, with a CEFR
+// level (A1-C2).
+type UserLanguage struct {
+	Language string `json:"language" validate:"required"`
+	Level    string `json:"level" validate:"required"`
+}
+
+// LanguageProfile is the full set of languages declared by a user.
+type LanguageProfile struct {
+	Timezone *string        `json:"timezone,omitempty"`
+	Speaks   []UserLanguage `json:"speaks"`
+	Learning []UserLanguage `json:"learning"`
+}
+
+// LanguagePartner is a ranked tandem match.
+type LanguagePartner struct {
+	ID              int      `json:"id"`
+	Username        string   `json:"username"`
+	FirstName       *string  `json:"first_name,omitempty"`
+	AvatarURL       *string  `json:"avatar_url,omitempty"`
+	CanTeach        []string `json:"can_teach"`
+	WantsToLearn    []string `json:"wants_to_learn"`
+	SharedInterests int      `json:"shared_interests"`
+	Score           float64  `json:"score"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// UpdateLocationRequest sets the caller's opt-in location. Coordinates are
+// rounded to roughly one kilometre before they are stored.
+type UpdateLocationRequest struct {
+	City          *string  `json:"city,omitempty"`
+	Latitude      *float64 `json:"latitude,omitempty" validate:"min=-90,max=90"`
+	Longitude     *float64 `json:"longitude,omitempty" validate:"min=-180,max=180"`
+	ShareLocation bool     `json:"share_location"`
+}
+
+// UserLocation is the caller's own stored location.
+type UserLocation struct {
+	City          *string  `json:"city,omitempty"`
+	Latitude      *float64 `json:"latitude,omitempty"`
+	Longitude     *float64 `json:"longitude,omitempty"`
+	ShareLocation bool     `json:"share_location"`
+}
+
+// NearbyUser is a public search result. It never carries coordinates, only
+// the city and a distance rounded up to whole kilometres.
+type NearbyUser struct {
+	ID         int     `json:"id"`
+	Username   string  `json:"username"`
+	FirstName  *string `json:"first_name,omitempty"`
+	LastName   *string `json:"last_name,omitempty"`
+	AvatarURL  *string `json:"avatar_url,omitempty"`
+	City       *string `json:"city,omitempty"`
+	DistanceKM int     `json:"distance_km"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+const (
+	MessagesFromEveryone  = "everyone"
+	MessagesFromFollowing = "following"
+)
+
+type Message struct {
+	ID             int64     `json:"id"`
+	ConversationID int       `json:"conversation_id"`
+	SenderID       int       `json:"sender_id"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Conversation is a one-to-one conversation as seen by one participant.
+type Conversation struct {
+	ID                     int        `json:"id"`
+	OtherUser              Connection `json:"other_user"`
+	LastMessage            *Message   `json:"last_message,omitempty"`
+	UnreadCount            int        `json:"unread_count"`
+	OtherLastReadMessageID int64      `json:"other_last_read_message_id"`
+	LastMessageAt          time.Time  `json:"last_message_at"`
+}
+
+type ConversationPage struct {
+	Items      []*Conversation `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+type MessagePage struct {
+	Items                  []*Message `json:"items"`
+	OtherLastReadMessageID int64      `json:"other_last_read_message_id"`
+	NextCursor             string     `json:"next_cursor,omitempty"`
+}
+
+type SendMessageRequest struct {
+	RecipientID int    `json:"recipient_id,omitempty"`
+	Body        string `json:"body" validate:"required"`
+}
+
+type MarkReadRequest struct {
+	MessageID *int64 `json:"message_id,omitempty"`
+}
+
+type MessagingSettings struct {
+	MessagesFrom string `json:"messages_from" validate:"required"`
+}
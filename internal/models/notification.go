@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	NotificationNewFollower   = "new_follower"
+	NotificationFollowRequest = "follow_request"
+	NotificationRSVPPromoted  = "rsvp_promoted"
+	NotificationCommentReply  = "comment_reply"
+)
+
+// NotificationDefaults lists every notification type with its default
+// preference, used when a user has not set one.
+var NotificationDefaults = []NotificationPreference{
+	{Type: NotificationNewFollower, InApp: true, Email: false},
+	{Type: NotificationFollowRequest, InApp: true, Email: true},
+	{Type: NotificationRSVPPromoted, InApp: true, Email: true},
+	{Type: NotificationCommentReply, InApp: true, Email: false},
+}
+
+type Notification struct {
+	ID         int64       `json:"id"`
+	UserID     int         `json:"-"`
+	Type       string      `json:"type"`
+	Actor      *Connection `json:"actor,omitempty"`
+	EntityType *string     `json:"entity_type,omitempty"`
+	EntityID   *int        `json:"entity_id,omitempty"`
+	ReadAt     *time.Time  `json:"read_at,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type NotificationPage struct {
+	Items       []*Notification `json:"items"`
+	UnreadCount int             `json:"unread_count"`
+	NextCursor  string          `json:"next_cursor,omitempty"`
+}
+
+type NotificationPreference struct {
+	Type  string `json:"type" validate:"required"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+}
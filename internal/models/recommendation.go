@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Recommendation is a cached "people you may want to meet" entry.
+type Recommendation struct {
+	ID              int       `json:"id"`
+	Username        string    `json:"username"`
+	FirstName       *string   `json:"first_name,omitempty"`
+	LastName        *string   `json:"last_name,omitempty"`
+	AvatarURL       *string   `json:"avatar_url,omitempty"`
+	SharedInterests int       `json:"shared_interests"`
+	Score           float64   `json:"score"`
+	ComputedAt      time.Time `json:"computed_at"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+const (
+	ReportTargetUser = "user"
+
+	ReportOpen      = "open"
+	ReportInReview  = "in_review"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+
+	ModerationWarn          = "warn"
+	ModerationSuspend       = "suspend"
+	ModerationDeleteContent = "delete_content"
+)
+
+// ReportReasons are the accepted report reason categories.
+var ReportReasons = []string{"spam", "harassment", "hate", "sexual", "violence", "impersonation", "other"}
+
+type Report struct {
+	ID             int        `json:"id"`
+	ReporterID     int        `json:"reporter_id"`
+	TargetType     string     `json:"target_type"`
+	TargetID       int        `json:"target_id"`
+	Reason         string     `json:"reason"`
+	Details        *string    `json:"details,omitempty"`
+	Status         string     `json:"status"`
+	AssigneeID     *int       `json:"assignee_id,omitempty"`
+	ResolutionNote *string    `json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+type CreateReportRequest struct {
+	TargetType string  `json:"target_type" validate:"required"`
+	TargetID   int     `json:"target_id" validate:"required"`
+	Reason     string  `json:"reason" validate:"required"`
+	Details    *string `json:"details,omitempty" validate:"max=2000"`
+}
+
+type AssignReportRequest struct {
+	AssigneeID *int `json:"assignee_id,omitempty"`
+}
+
+type ResolveReportRequest struct {
+	Status string  `json:"status" validate:"required"`
+	Note   *string `json:"note,omitempty"`
+}
+
+type ModerationActionRequest struct {
+	Action string  `json:"action" validate:"required"`
+	Note   *string `json:"note,omitempty"`
+}
+
+type ModerationAction struct {
+	ID           int       `json:"id"`
+	ReportID     *int      `json:"report_id,omitempty"`
+	ModeratorID  int       `json:"moderator_id"`
+	TargetUserID int       `json:"target_user_id"`
+	Action       string    `json:"action"`
+	Note         *string   `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
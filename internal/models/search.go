@@ -0,0 +1,36 @@
+package models
+
+const (
+	SearchTypeMember = "member"
+	SearchTypeGroup  = "group"
+)
+
+// SearchResult is one ranked hit. Snippet is the matching part of the body
+// (bio or description) as HTML: the text is escaped and matched words are
+// wrapped in <mark> tags.
+type SearchResult struct {
+	Type     string  `json:"type"`
+	ID       int     `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle *string `json:"subtitle,omitempty"`
+	ImageURL *string `json:"image_url,omitempty"`
+	Snippet  string  `json:"snippet,omitempty"`
+	Rank     float64 `json:"rank"`
+}
+
+// SearchResponse carries one page of results and the total number of hits
+// per result type, ignoring any type filter.
+type SearchResponse struct {
+	Query   string          `json:"query"`
+	Results []*SearchResult `json:"results"`
+	Facets  map[string]int  `json:"facets"`
+}
+
+// SearchLanguages maps the supported lang parameter values to the Postgres
+// text search configurations used for stemming.
+var SearchLanguages = map[string]string{
+	"en": "english",
+	"es": "spanish",
+	"fr": "french",
+	"de": "german",
+}
@@ -21,8 +21,8 @@ type User struct {
 
 type RegisterRequest struct {
 	Email     string   `json:"email" validate:"required,email"`
-	Username  string   `json:"username" validate:"required,min=3,max=100"`
-	Password  string   `json:"password" validate:"required,min=8"`
+	Username  string   `json:"username" validate:"required,username"`
+	Password  string   `json:"password" validate:"required,password"`
 	FirstName *string  `json:"first_name,omitempty"`
 	LastName  *string  `json:"last_name,omitempty"`
 	Interests []int    `json:"interests,omitempty"`
@@ -39,7 +39,7 @@ type PasswordResetRequest struct {
 
 type PasswordResetConfirm struct {
 	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=8"`
+	NewPassword string `json:"new_password" validate:"required,password"`
 }
 
 type AuthResponse struct {
@@ -47,6 +47,12 @@ type AuthResponse struct {
 	User  *User  `json:"user"`
 }
 
+// LiveTicket authenticates one WebSocket or SSE connection.
+type LiveTicket struct {
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
 type PasswordResetToken struct {
 	ID        int       `json:"id"`
 	UserID    int       `json:"user_id"`
@@ -0,0 +1,83 @@
+package realtime
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// notifyChannel is the Postgres channel every API replica listens on.
+const notifyChannel = "realtime_events"
+
+// maxNotifyPayload stays below Postgres' 8000 byte NOTIFY payload limit.
+const maxNotifyPayload = 7900
+
+// Bus carries envelopes between API replicas.
+type Bus interface {
+	// NextID returns a cluster-wide increasing event ID.
+	NextID() (int64, error)
+	Publish(payload []byte) error
+	// Listen delivers every published payload, including the replica's own,
+	// until stop is closed.
+	Listen(deliver func(payload []byte), stop <-chan struct{})
+}
+
+// PostgresBus is a Bus on top of LISTEN/NOTIFY.
+type PostgresBus struct {
+	db          *sql.DB
+	databaseURL string
+}
+
+func NewPostgresBus(db *sql.DB, databaseURL string) *PostgresBus {
+	return &PostgresBus{db: db, databaseURL: databaseURL}
+}
+
+func (b *PostgresBus) NextID() (int64, error) {
+	var id int64
+	if err := b.db.QueryRow(`SELECT nextval('realtime_event_seq')`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to allocate realtime event id: %w", err)
+	}
+	return id, nil
+}
+
+func (b *PostgresBus) Publish(payload []byte) error {
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("realtime payload of %d bytes exceeds the NOTIFY limit", len(payload))
+	}
+	if _, err := b.db.Exec(`SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish realtime event: %w", err)
+	}
+	return nil
+}
+
+func (b *PostgresBus) Listen(deliver func(payload []byte), stop <-chan struct{}) {
+	listener := pq.NewListener(b.databaseURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("realtime: listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(notifyChannel); err != nil {
+		log.Printf("realtime: failed to listen on %s: %v", notifyChannel, err)
+		return
+	}
+
+	for {
+		select {
+		case n := <-listener.Notify:
+			// A nil notification means the connection was re-established and
+			// events may have been missed; clients resync on their own.
+			if n != nil {
+				deliver([]byte(n.Extra))
+			}
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		case <-stop:
+			return
+		}
+	}
+}
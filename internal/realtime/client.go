@@ -0,0 +1,156 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"windsurf-project/internal/service"
+)
+
+const (
+	writeWait      = 10 * time.Second
+	pongWait       = 60 * time.Second
+	pingPeriod     = (pongWait * 9) / 10
+	maxFrameSize   = 4096
+	sendBufferSize = 64
+)
+
+type clientFrame struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Client is one WebSocket connection. Outgoing frames are buffered; a client
+// that falls sendBufferSize frames behind is disconnected rather than
+// allowed to hold up delivery to everyone else.
+type Client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	userID int
+	send   chan []byte
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, userID int) *Client {
+	return &Client{
+		hub:    hub,
+		conn:   conn,
+		userID: userID,
+		send:   make(chan []byte, sendBufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+func (c *Client) enqueue(frame []byte) {
+	select {
+	case c.send <- frame:
+	case <-c.done:
+	default:
+		c.hub.unregister(c)
+	}
+}
+
+func (c *Client) reply(ev *Event) {
+	frame, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	c.enqueue(frame)
+}
+
+func (c *Client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// readPump reads client frames until the connection fails or the peer stops
+// answering pings.
+func (c *Client) readPump() {
+	defer c.hub.unregister(c)
+
+	c.conn.SetReadLimit(maxFrameSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, raw, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var frame clientFrame
+		if err := json.Unmarshal(raw, &frame); err != nil {
+			c.reply(errorEvent("invalid frame"))
+			continue
+		}
+
+		handler, ok := c.hub.handler(frame.Type)
+		if !ok {
+			c.reply(errorEvent("unknown frame type"))
+			continue
+		}
+
+		ev, err := handler(c.userID, frame.Data)
+		if err != nil {
+			c.reply(errorEvent(c.errorMessage(frame.Type, err)))
+			continue
+		}
+		if ev != nil {
+			c.reply(ev)
+		}
+	}
+}
+
+// writePump writes queued frames and pings the peer on every heartbeat.
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case frame := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				c.hub.unregister(c)
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.hub.unregister(c)
+				return
+			}
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		}
+	}
+}
+
+// errorMessage returns what the client is told about a failed frame: the
+// message of a service error, or a generic one for internal failures, which
+// are logged instead because their text may describe the database.
+func (c *Client) errorMessage(frameType string, err error) string {
+	if msg := service.Message(err); msg != "" {
+		return msg
+	}
+	log.Printf("realtime: %s frame from user %d: %v", frameType, c.userID, err)
+	return "internal error"
+}
+
+func errorEvent(message string) *Event {
+	return &Event{Type: "error", Data: map[string]string{"message": message}}
+}
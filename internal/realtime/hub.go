@@ -0,0 +1,401 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"windsurf-project/internal/repository"
+	"windsurf-project/internal/service"
+)
+
+const (
+	// HeartbeatInterval is how often the hub refreshes presence rows; rows
+	// older than three intervals are treated as belonging to a dead replica.
+	HeartbeatInterval = 30 * time.Second
+
+	replayBufferSize = 1000
+
+	presenceOnline  = "presence.online"
+	presenceOffline = "presence.offline"
+)
+
+// Event is the JSON frame exchanged with clients.
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+	// Truncated is set when the event was too large for the bus and clients
+	// should refetch the resource instead.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// InboundHandler processes a frame sent by a client. A returned event is sent
+// back to that client only. Errors are reported to the client the way
+// handlers report them over HTTP: only service errors keep their message.
+type InboundHandler func(userID int, data json.RawMessage) (*Event, error)
+
+// envelope is what travels on the bus. An envelope with Disconnect set
+// carries no event; it tells every replica to drop the users' connections.
+type envelope struct {
+	ID         int64           `json:"id"`
+	UserIDs    []int           `json:"user_ids"`
+	Event      json.RawMessage `json:"event,omitempty"`
+	Disconnect bool            `json:"disconnect,omitempty"`
+}
+
+// Hub fans events out to the WebSocket connections and stream subscriptions
+// held by this replica. Events are always published on the bus, and every
+// replica (including the publisher) delivers them to its local connections,
+// so users connected to different replicas all receive them.
+type Hub struct {
+	bus        Bus
+	presence   *repository.PresenceRepository
+	instanceID string
+	replay     *replayBuffer
+
+	mu       sync.RWMutex
+	clients  map[int]map[*Client]struct{}
+	subs     map[int]map[*Subscription]struct{}
+	handlers map[string]InboundHandler
+}
+
+func NewHub(bus Bus, presence *repository.PresenceRepository) *Hub {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		panic(fmt.Sprintf("realtime: failed to generate instance id: %v", err))
+	}
+
+	h := &Hub{
+		bus:        bus,
+		presence:   presence,
+		instanceID: hex.EncodeToString(id),
+		replay:     newReplayBuffer(replayBufferSize),
+		clients:    make(map[int]map[*Client]struct{}),
+		subs:       make(map[int]map[*Subscription]struct{}),
+		handlers:   make(map[string]InboundHandler),
+	}
+	h.Handle("presence.query", h.queryPresence)
+	return h
+}
+
+// Handle registers the handler for a client frame type.
+func (h *Hub) Handle(eventType string, handler InboundHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[eventType] = handler
+}
+
+// PublishToUsers sends an event to every live connection of the given users
+// on any replica.
+func (h *Hub) PublishToUsers(userIDs []int, eventType string, data interface{}) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	id, err := h.bus.NextID()
+	if err != nil {
+		return err
+	}
+
+	payload, err := h.encode(id, userIDs, Event{Type: eventType, Data: data})
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		if payload, err = h.encode(id, userIDs, Event{Type: eventType, Truncated: true}); err != nil {
+			return err
+		}
+	}
+
+	return h.bus.Publish(payload)
+}
+
+// DisconnectUser closes every WebSocket connection and stream of userID on
+// any replica, e.g. once the account is suspended. Reconnecting fails as long
+// as the user's tokens stay revoked.
+func (h *Hub) DisconnectUser(userID int) error {
+	payload, err := json.Marshal(envelope{UserIDs: []int{userID}, Disconnect: true})
+	if err != nil {
+		return fmt.Errorf("failed to encode realtime disconnect: %w", err)
+	}
+	return h.bus.Publish(payload)
+}
+
+// Subscribe registers a stream for userID. When resuming, the frames after
+// lastEventID still held in the replay buffer are returned; ok is false if
+// some were already evicted and the client must resync.
+func (h *Hub) Subscribe(userID int, lastEventID int64, resume bool) (sub *Subscription, missed []Frame, ok bool) {
+	sub = newSubscription(h, userID)
+
+	h.mu.Lock()
+	subs, exists := h.subs[userID]
+	if !exists {
+		subs = make(map[*Subscription]struct{})
+		h.subs[userID] = subs
+	}
+	subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	if !resume {
+		return sub, nil, true
+	}
+	missed, ok = h.replay.since(userID, lastEventID)
+	return sub, missed, ok
+}
+
+// Unsubscribe removes a stream subscription.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	if subs, ok := h.subs[sub.userID]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subs, sub.userID)
+		}
+	}
+	h.mu.Unlock()
+
+	sub.close()
+}
+
+// Run listens on the bus and keeps this replica's presence rows fresh until
+// stop is closed, then disconnects every client.
+func (h *Hub) Run(stop <-chan struct{}) {
+	go h.bus.Listen(h.deliver, stop)
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := h.presence.Heartbeat(context.Background(), h.instanceID); err != nil {
+				log.Printf("realtime: %v", err)
+			}
+		case <-stop:
+			h.closeAll()
+			if err := h.presence.ClearInstance(context.Background(), h.instanceID); err != nil {
+				log.Printf("realtime: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// Serve attaches an upgraded connection for an authenticated user and blocks
+// until it closes.
+func (h *Hub) Serve(conn *websocket.Conn, userID int) {
+	c := newClient(h, conn, userID)
+	h.register(c)
+	go c.writePump()
+	c.readPump()
+}
+
+func (h *Hub) encode(id int64, userIDs []int, ev Event) ([]byte, error) {
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode realtime event: %w", err)
+	}
+	payload, err := json.Marshal(envelope{ID: id, UserIDs: userIDs, Event: raw})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode realtime event: %w", err)
+	}
+	return payload, nil
+}
+
+func (h *Hub) deliver(payload []byte) {
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		log.Printf("realtime: dropping malformed envelope: %v", err)
+		return
+	}
+	if env.Disconnect {
+		for _, userID := range env.UserIDs {
+			h.disconnect(userID)
+		}
+		return
+	}
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(env.Event, &head); err != nil {
+		log.Printf("realtime: dropping malformed event: %v", err)
+		return
+	}
+
+	frame := Frame{ID: env.ID, Type: head.Type, Data: env.Event}
+	h.replay.add(env.UserIDs, frame)
+
+	h.mu.RLock()
+	clients := []*Client{}
+	subs := []*Subscription{}
+	for _, userID := range env.UserIDs {
+		for c := range h.clients[userID] {
+			clients = append(clients, c)
+		}
+		for s := range h.subs[userID] {
+			subs = append(subs, s)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		c.enqueue(env.Event)
+	}
+	for _, s := range subs {
+		s.enqueue(frame)
+	}
+}
+
+func (h *Hub) register(c *Client) {
+	h.mu.Lock()
+	conns, ok := h.clients[c.userID]
+	if !ok {
+		conns = make(map[*Client]struct{})
+		h.clients[c.userID] = conns
+	}
+	conns[c] = struct{}{}
+	first := len(conns) == 1
+	h.mu.Unlock()
+
+	if first {
+		go h.updatePresence(c.userID, true)
+	}
+}
+
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	conns, ok := h.clients[c.userID]
+	if !ok {
+		h.mu.Unlock()
+		return
+	}
+	if _, ok := conns[c]; !ok {
+		h.mu.Unlock()
+		return
+	}
+	delete(conns, c)
+	last := len(conns) == 0
+	if last {
+		delete(h.clients, c.userID)
+	}
+	h.mu.Unlock()
+
+	c.close()
+	if last {
+		go h.updatePresence(c.userID, false)
+	}
+}
+
+// updatePresence records the change for this replica and, when the user
+// actually came online or went offline across all replicas, tells their
+// conversation partners.
+func (h *Hub) updatePresence(userID int, online bool) {
+	var elsewhere bool
+	var err error
+	if online {
+		elsewhere, err = h.presence.MarkOnline(context.Background(), userID, h.instanceID)
+	} else {
+		elsewhere, err = h.presence.MarkOffline(context.Background(), userID, h.instanceID)
+	}
+	if err != nil {
+		log.Printf("realtime: %v", err)
+		return
+	}
+	if elsewhere {
+		return
+	}
+
+	audience, err := h.presence.Audience(context.Background(), userID)
+	if err != nil {
+		log.Printf("realtime: %v", err)
+		return
+	}
+
+	eventType := presenceOffline
+	if online {
+		eventType = presenceOnline
+	}
+	if err := h.PublishToUsers(audience, eventType, map[string]int{"user_id": userID}); err != nil {
+		log.Printf("realtime: %v", err)
+	}
+}
+
+func (h *Hub) queryPresence(userID int, data json.RawMessage) (*Event, error) {
+	var req struct {
+		UserIDs []int `json:"user_ids"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, service.Validation("invalid presence query")
+	}
+	if len(req.UserIDs) > 200 {
+		return nil, service.Validation("at most 200 users can be queried at once")
+	}
+
+	online, err := h.presence.OnlineUsers(context.Background(), userID, req.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{Type: "presence.state", Data: map[string][]int{"online": online}}, nil
+}
+
+func (h *Hub) handler(eventType string) (InboundHandler, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	fn, ok := h.handlers[eventType]
+	return fn, ok
+}
+
+// disconnect drops the local connections and streams of userID.
+func (h *Hub) disconnect(userID int) {
+	h.mu.RLock()
+	clients := []*Client{}
+	for c := range h.clients[userID] {
+		clients = append(clients, c)
+	}
+	subs := []*Subscription{}
+	for s := range h.subs[userID] {
+		subs = append(subs, s)
+	}
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		h.unregister(c)
+	}
+	for _, s := range subs {
+		h.Unsubscribe(s)
+	}
+}
+
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	all := []*Client{}
+	for _, conns := range h.clients {
+		for c := range conns {
+			all = append(all, c)
+		}
+	}
+	subs := []*Subscription{}
+	for _, userSubs := range h.subs {
+		for s := range userSubs {
+			subs = append(subs, s)
+		}
+	}
+	h.clients = make(map[int]map[*Client]struct{})
+	h.subs = make(map[int]map[*Subscription]struct{})
+	h.mu.Unlock()
+
+	for _, c := range all {
+		c.close()
+	}
+	for _, s := range subs {
+		s.close()
+	}
+}
@@ -0,0 +1,65 @@
+package realtime
+
+import "sync"
+
+// Frame is a delivered event with its cluster-wide ID.
+type Frame struct {
+	ID   int64
+	Type string
+	Data []byte
+}
+
+type bufferedFrame struct {
+	userIDs []int
+	frame   Frame
+}
+
+// replayBuffer keeps the most recent frames so stream clients can resume
+// after a reconnect with Last-Event-ID. Every replica receives every event
+// from the bus, so any replica can serve a resume.
+type replayBuffer struct {
+	mu     sync.RWMutex
+	size   int
+	frames []bufferedFrame
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{size: size, frames: make([]bufferedFrame, 0, size)}
+}
+
+func (b *replayBuffer) add(userIDs []int, frame Frame) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.frames) == b.size {
+		copy(b.frames, b.frames[1:])
+		b.frames = b.frames[:b.size-1]
+	}
+	b.frames = append(b.frames, bufferedFrame{userIDs: userIDs, frame: frame})
+}
+
+// since returns the frames for userID with an ID greater than lastID. ok is
+// false when lastID is older than the buffer, meaning frames were lost and
+// the client has to resync.
+func (b *replayBuffer) since(userID int, lastID int64) ([]Frame, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.frames) > 0 && b.frames[0].frame.ID > lastID+1 {
+		return nil, false
+	}
+
+	frames := []Frame{}
+	for _, bf := range b.frames {
+		if bf.frame.ID <= lastID {
+			continue
+		}
+		for _, id := range bf.userIDs {
+			if id == userID {
+				frames = append(frames, bf.frame)
+				break
+			}
+		}
+	}
+	return frames, true
+}
@@ -0,0 +1,50 @@
+package realtime
+
+import "sync"
+
+// Subscription receives the frames addressed to one user, for transports
+// such as Server-Sent Events that manage their own connection. Like a
+// WebSocket client, a subscription that falls sendBufferSize frames behind
+// is closed; the client is expected to reconnect and resume.
+type Subscription struct {
+	hub    *Hub
+	userID int
+	frames chan Frame
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newSubscription(hub *Hub, userID int) *Subscription {
+	return &Subscription{
+		hub:    hub,
+		userID: userID,
+		frames: make(chan Frame, sendBufferSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// Frames delivers frames in arrival order.
+func (s *Subscription) Frames() <-chan Frame {
+	return s.frames
+}
+
+// Done is closed when the subscription is dropped.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) enqueue(frame Frame) {
+	select {
+	case s.frames <- frame:
+	case <-s.done:
+	default:
+		s.hub.Unsubscribe(s)
+	}
+}
+
+func (s *Subscription) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
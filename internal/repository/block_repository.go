@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"windsurf-project/internal/models"
+)
+
+type BlockRepository struct {
+	db *sql.DB
+}
+
+func NewBlockRepository(db *sql.DB) *BlockRepository {
+	return &BlockRepository{db: db}
+}
+
+// Block records a block and removes any follow edges and cached
+// recommendations between the two users.
+func (r *BlockRepository) Block(ctx context.Context, blockerID, blockedID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		`DELETE FROM follows WHERE (follower_id = $1 AND followee_id = $2) OR (follower_id = $2 AND followee_id = $1)`,
+		`DELETE FROM user_recommendations WHERE (user_id = $1 AND recommended_user_id = $2) OR (user_id = $2 AND recommended_user_id = $1)`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt, blockerID, blockedID); err != nil {
+			return fmt.Errorf("failed to block user: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BlockRepository) Unblock(ctx context.Context, blockerID, blockedID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+	if _, err := r.db.ExecContext(ctx, query, blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	return nil
+}
+
+// IsBlocked reports whether either user has blocked the other.
+func (r *BlockRepository) IsBlocked(ctx context.Context, userA, userB int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var blocked bool
+	query := `SELECT NOT ` + notBlocked("$1", "$2")
+	if err := r.db.QueryRowContext(ctx, query, userA, userB).Scan(&blocked); err != nil {
+		return false, fmt.Errorf("failed to check block: %w", err)
+	}
+	return blocked, nil
+}
+
+func (r *BlockRepository) ListBlocked(ctx context.Context, userID int) ([]*models.BlockedUser, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	return r.list(ctx, `
+		SELECT u.id, u.username, u.avatar_url, b.created_at
+		FROM user_blocks b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC
+	`, userID)
+}
+
+func (r *BlockRepository) Mute(ctx context.Context, muterID, mutedID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `INSERT INTO user_mutes (muter_id, muted_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := r.db.ExecContext(ctx, query, muterID, mutedID); err != nil {
+		return fmt.Errorf("failed to mute user: %w", err)
+	}
+	return nil
+}
+
+func (r *BlockRepository) Unmute(ctx context.Context, muterID, mutedID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `DELETE FROM user_mutes WHERE muter_id = $1 AND muted_id = $2`
+	if _, err := r.db.ExecContext(ctx, query, muterID, mutedID); err != nil {
+		return fmt.Errorf("failed to unmute user: %w", err)
+	}
+	return nil
+}
+
+func (r *BlockRepository) ListMuted(ctx context.Context, userID int) ([]*models.BlockedUser, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	return r.list(ctx, `
+		SELECT u.id, u.username, u.avatar_url, m.created_at
+		FROM user_mutes m
+		JOIN users u ON u.id = m.muted_id
+		WHERE m.muter_id = $1
+		ORDER BY m.created_at DESC
+	`, userID)
+}
+
+func (r *BlockRepository) list(ctx context.Context, query string, userID int) ([]*models.BlockedUser, error) {
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*models.BlockedUser{}
+	for rows.Next() {
+		u := &models.BlockedUser{}
+		if err := rows.Scan(&u.ID, &u.Username, &u.AvatarURL, &u.Since); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
+
+// IsMuted reports whether muterID has muted mutedID.
+func (r *BlockRepository) IsMuted(ctx context.Context, muterID, mutedID int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var muted bool
+	query := `SELECT EXISTS (SELECT 1 FROM user_mutes WHERE muter_id = $1 AND muted_id = $2)`
+	if err := r.db.QueryRowContext(ctx, query, muterID, mutedID).Scan(&muted); err != nil {
+		return false, fmt.Errorf("failed to check mute: %w", err)
+	}
+	return muted, nil
+}
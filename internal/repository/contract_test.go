@@ -0,0 +1,54 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"windsurf-project/internal/database"
+	"windsurf-project/internal/repository"
+	"windsurf-project/internal/repository/repotest"
+)
+
+// TestPostgresContract runs the store contract against a real database. It
+// needs TEST_DATABASE_URL and empties the users, interest and follow tables
+// of that database before every subtest, so never point it at real data.
+func TestPostgresContract(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := database.NewPostgresDB(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := database.RunMigrations(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		truncate(t, db)
+		return repotest.Backend{
+			Stores: repository.Stores{
+				Users:     repository.NewUserRepository(db),
+				Tokens:    repository.NewTokenRepository(db),
+				Interests: repository.NewInterestRepository(db),
+				Follows:   repository.NewFollowRepository(db),
+			},
+			UnitOfWork: repository.NewUnitOfWork(db),
+		}
+	})
+}
+
+func truncate(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	_, err := db.Exec(`TRUNCATE users, password_reset_tokens, interest_groups, user_interests, follows RESTART IDENTITY CASCADE`)
+	if err != nil {
+		t.Fatalf("failed to empty tables: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"windsurf-project/internal/models"
+)
+
+type DigestRepository struct {
+	db *sql.DB
+}
+
+func NewDigestRepository(db *sql.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+// digestPeriod maps a frequency to the SQL interval between two digests.
+const digestPeriod = `CASE u.digest_frequency WHEN 'daily' THEN INTERVAL '1 day' ELSE INTERVAL '7 days' END`
+
+// ClaimDue claims up to limit active users with IDs above afterID whose
+// digest is due at now, in ID order, by recording now as their last digest.
+// Rows another replica is claiming are skipped and claimed rows are no
+// longer due, so each digest is sent by one replica only. A user who has
+// never received a digest is covered from one period back.
+func (r *DigestRepository) ClaimDue(ctx context.Context, now time.Time, afterID, limit int) ([]*models.DigestRecipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Batch)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		WITH due AS (
+			SELECT u.id, u.digest_sent_at AS previous,
+			       COALESCE(u.digest_sent_at, $1::timestamp - `+digestPeriod+`) AS since
+			FROM users u
+			WHERE u.is_active = TRUE AND u.digest_frequency <> 'off' AND u.id > $2
+			  AND (u.digest_sent_at IS NULL OR u.digest_sent_at <= $1::timestamp - `+digestPeriod+`)
+			ORDER BY u.id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE users u SET digest_sent_at = $1
+		FROM due
+		WHERE u.id = due.id
+		RETURNING u.id, u.email, u.username, u.digest_frequency, due.since, due.previous
+	`, now, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim digest recipients: %w", err)
+	}
+	defer rows.Close()
+
+	recipients := []*models.DigestRecipient{}
+	for rows.Next() {
+		rc := &models.DigestRecipient{}
+		if err := rows.Scan(&rc.ID, &rc.Email, &rc.Username, &rc.Frequency, &rc.Since, &rc.PreviousSentAt); err != nil {
+			return nil, fmt.Errorf("failed to scan digest recipient: %w", err)
+		}
+		recipients = append(recipients, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim digest recipients: %w", err)
+	}
+
+	// UPDATE ... RETURNING does not keep the CTE's order.
+	sort.Slice(recipients, func(i, j int) bool { return recipients[i].ID < recipients[j].ID })
+	return recipients, nil
+}
+
+// NewMembers returns, per interest group of userID, the members who joined it
+// after since, newest first and at most perGroup each. Groups without new
+// members are omitted.
+func (r *DigestRepository) NewMembers(ctx context.Context, userID int, since time.Time, perGroup int) ([]*models.DigestGroup, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT g.id, g.name, COUNT(*) OVER (PARTITION BY g.id),
+		       u.id, u.username, u.first_name
+		FROM user_interests mine
+		JOIN interest_groups g ON g.id = mine.interest_id
+		JOIN user_interests ui ON ui.interest_id = mine.interest_id
+		                      AND ui.user_id <> mine.user_id
+		                      AND ui.joined_at > $2
+		JOIN users u ON u.id = ui.user_id
+		WHERE mine.user_id = $1 AND u.is_active = TRUE
+		  AND `+notBlocked("$1", "u.id")+`
+		ORDER BY g.id, ui.joined_at DESC, u.id
+	`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list new members: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []*models.DigestGroup{}
+	var current *models.DigestGroup
+	for rows.Next() {
+		var g models.DigestGroup
+		m := &models.DigestMember{}
+		if err := rows.Scan(&g.InterestID, &g.Name, &g.NewMemberCount, &m.ID, &m.Username, &m.FirstName); err != nil {
+			return nil, fmt.Errorf("failed to scan new member: %w", err)
+		}
+		if current == nil || current.InterestID != g.InterestID {
+			current = &g
+			groups = append(groups, current)
+		}
+		if len(current.NewMembers) < perGroup {
+			current.NewMembers = append(current.NewMembers, m)
+		}
+	}
+
+	return groups, rows.Err()
+}
+
+// ReleaseClaim undoes the claim made at claimedAt by ClaimDue after the
+// digest could not be sent, so it is retried on the next run.
+func (r *DigestRepository) ReleaseClaim(ctx context.Context, rc *models.DigestRecipient, claimedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET digest_sent_at = $1 WHERE id = $2 AND digest_sent_at = $3`, rc.PreviousSentAt, rc.ID, claimedAt)
+	if err != nil {
+		return fmt.Errorf("failed to release digest claim: %w", err)
+	}
+	return nil
+}
+
+func (r *DigestRepository) GetSettings(ctx context.Context, userID int) (*models.DigestSettings, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	settings := &models.DigestSettings{}
+	query := `SELECT digest_frequency FROM users WHERE id = $1 AND is_active = TRUE`
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&settings.Frequency)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *DigestRepository) UpdateSettings(ctx context.Context, userID int, settings *models.DigestSettings) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `UPDATE users SET digest_frequency = $1, updated_at = $2 WHERE id = $3`
+	result, err := r.db.ExecContext(ctx, query, settings.Frequency, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update digest settings: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	return nil
+}
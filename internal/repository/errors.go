@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Repositories wrap these so callers can tell a missing row or a duplicate
+// from a database failure, e.g. fmt.Errorf("user %w", ErrNotFound) reads
+// "user not found".
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("already exists")
+
+	ErrEmailTaken    = fmt.Errorf("email %w", ErrConflict)
+	ErrUsernameTaken = fmt.Errorf("username %w", ErrConflict)
+)
+
+// uniqueViolation returns the name of the unique constraint err violated.
+func uniqueViolation(err error) (string, bool) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return pqErr.Constraint, true
+	}
+	return "", false
+}
+
+func foreignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique violation that
+// no repository translated into ErrConflict.
+func IsUniqueViolation(err error) bool {
+	_, ok := uniqueViolation(err)
+	return ok
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"windsurf-project/internal/models"
+)
+
+// FollowRepository stores the follower graph. Both directions are covered by
+// composite indexes on (user, status, created_at, other user) so list pages
+// and counts stay index-only for members with many connections.
+type FollowRepository struct {
+	db dbtx
+}
+
+func NewFollowRepository(db *sql.DB) *FollowRepository {
+	return &FollowRepository{db: db}
+}
+
+// Follow creates a follow edge with the given status and returns the status
+// the edge ends up with and whether it was newly created. An existing edge is
+// left untouched.
+func (r *FollowRepository) Follow(ctx context.Context, followerID, followeeID int, status string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		INSERT INTO follows (follower_id, followee_id, status, accepted_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (follower_id, followee_id) DO UPDATE SET status = follows.status
+		RETURNING status, (xmax = 0)
+	`
+
+	var acceptedAt *time.Time
+	if status == models.FollowAccepted {
+		now := time.Now()
+		acceptedAt = &now
+	}
+
+	var result string
+	var created bool
+	err := r.db.QueryRowContext(ctx, query, followerID, followeeID, status, acceptedAt).Scan(&result, &created)
+	if foreignKeyViolation(err) {
+		return "", false, fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to follow user: %w", err)
+	}
+
+	return result, created, nil
+}
+
+// Unfollow removes a follow edge or withdraws a pending request.
+func (r *FollowRepository) Unfollow(ctx context.Context, followerID, followeeID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`
+	_, err := r.db.ExecContext(ctx, query, followerID, followeeID)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	return nil
+}
+
+// AcceptRequest accepts a pending follow request addressed to followeeID.
+func (r *FollowRepository) AcceptRequest(ctx context.Context, followeeID, followerID int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		UPDATE follows SET status = 'accepted', accepted_at = NOW()
+		WHERE follower_id = $1 AND followee_id = $2 AND status = 'pending'
+	`
+	res, err := r.db.ExecContext(ctx, query, followerID, followeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to accept follow request: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to accept follow request: %w", err)
+	}
+	return n > 0, nil
+}
+
+// RejectRequest deletes a pending follow request addressed to followeeID.
+func (r *FollowRepository) RejectRequest(ctx context.Context, followeeID, followerID int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2 AND status = 'pending'`
+	res, err := r.db.ExecContext(ctx, query, followerID, followeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to reject follow request: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to reject follow request: %w", err)
+	}
+	return n > 0, nil
+}
+
+// AcceptAllPending accepts every pending request, used when a profile is
+// switched from private to public.
+func (r *FollowRepository) AcceptAllPending(ctx context.Context, followeeID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `UPDATE follows SET status = 'accepted', accepted_at = NOW() WHERE followee_id = $1 AND status = 'pending'`
+	if _, err := r.db.ExecContext(ctx, query, followeeID); err != nil {
+		return fmt.Errorf("failed to accept follow requests: %w", err)
+	}
+	return nil
+}
+
+// GetStatus returns the status of the edge from followerID to followeeID, or
+// an empty string when there is none.
+func (r *FollowRepository) GetStatus(ctx context.Context, followerID, followeeID int) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var status string
+	query := `SELECT status FROM follows WHERE follower_id = $1 AND followee_id = $2`
+	err := r.db.QueryRowContext(ctx, query, followerID, followeeID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get follow status: %w", err)
+	}
+	return status, nil
+}
+
+// Mutual returns the status of the edge from userID to otherID, or an empty
+// string when there is none, and whether otherID follows userID back.
+func (r *FollowRepository) Mutual(ctx context.Context, userID, otherID int) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		SELECT
+			COALESCE((SELECT status FROM follows WHERE follower_id = $1 AND followee_id = $2), ''),
+			EXISTS (SELECT 1 FROM follows WHERE follower_id = $2 AND followee_id = $1 AND status = 'accepted')
+	`
+	var status string
+	var followedBack bool
+	if err := r.db.QueryRowContext(ctx, query, userID, otherID).Scan(&status, &followedBack); err != nil {
+		return "", false, fmt.Errorf("failed to get follow relationship: %w", err)
+	}
+	return status, followedBack, nil
+}
+
+// Counts returns accepted follower, following and mutual friend counts.
+// Deactivated members are not counted, as they are not listed.
+func (r *FollowRepository) Counts(ctx context.Context, userID int) (followers, following, friends int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		SELECT
+			(SELECT COUNT(*)
+			 FROM follows f JOIN users u ON u.id = f.follower_id AND u.is_active = TRUE
+			 WHERE f.followee_id = $1 AND f.status = 'accepted'),
+			(SELECT COUNT(*)
+			 FROM follows f JOIN users u ON u.id = f.followee_id AND u.is_active = TRUE
+			 WHERE f.follower_id = $1 AND f.status = 'accepted'),
+			(SELECT COUNT(*)
+			 FROM follows f
+			 JOIN follows b ON b.follower_id = f.followee_id AND b.followee_id = f.follower_id AND b.status = 'accepted'
+			 JOIN users u ON u.id = f.followee_id AND u.is_active = TRUE
+			 WHERE f.follower_id = $1 AND f.status = 'accepted')
+	`
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&followers, &following, &friends); err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to count connections: %w", err)
+	}
+	return followers, following, friends, nil
+}
+
+// ListFollowers pages through users following userID with the given status,
+// newest first, hiding deactivated members and anyone who has a block with
+// viewerID. beforeAt and beforeID are the keyset position of the previous
+// page's last item; pass a zero time for the first page.
+func (r *FollowRepository) ListFollowers(ctx context.Context, viewerID, userID int, status string, beforeAt time.Time, beforeID, limit int) ([]*models.Connection, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	return r.list(ctx, "followee_id", "follower_id", viewerID, userID, status, beforeAt, beforeID, limit)
+}
+
+// ListFollowing pages through users that userID follows, newest first.
+func (r *FollowRepository) ListFollowing(ctx context.Context, viewerID, userID int, beforeAt time.Time, beforeID, limit int) ([]*models.Connection, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	return r.list(ctx, "follower_id", "followee_id", viewerID, userID, models.FollowAccepted, beforeAt, beforeID, limit)
+}
+
+func (r *FollowRepository) list(ctx context.Context, ownerCol, otherCol string, viewerID, userID int, status string, beforeAt time.Time, beforeID, limit int) ([]*models.Connection, error) {
+	args := []interface{}{userID, status, limit, viewerID}
+	keyset := ""
+	if !beforeAt.IsZero() {
+		keyset = fmt.Sprintf("AND (f.created_at, f.%s) < ($5, $6)", otherCol)
+		args = append(args, beforeAt, beforeID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT u.id, u.username, u.first_name, u.last_name, u.avatar_url, f.created_at
+		FROM follows f
+		JOIN users u ON u.id = f.%[2]s AND u.is_active = TRUE
+		WHERE f.%[1]s = $1 AND f.status = $2 %[3]s
+		  AND %[4]s
+		ORDER BY f.created_at DESC, f.%[2]s DESC
+		LIMIT $3
+	`, ownerCol, otherCol, keyset, notBlocked("$4", "u.id"))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	defer rows.Close()
+
+	conns := []*models.Connection{}
+	for rows.Next() {
+		c := &models.Connection{}
+		if err := rows.Scan(&c.ID, &c.Username, &c.FirstName, &c.LastName, &c.AvatarURL, &c.Since); err != nil {
+			return nil, fmt.Errorf("failed to scan connection: %w", err)
+		}
+		conns = append(conns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+
+	return conns, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type InterestRepository struct {
+	db dbtx
+}
+
+func NewInterestRepository(db *sql.DB) *InterestRepository {
+	return &InterestRepository{db: db}
+}
+
+// Upsert creates an interest group or updates the description and icon of
+// the existing group with the same name, and returns its ID.
+func (r *InterestRepository) Upsert(ctx context.Context, name string, description, iconURL *string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var id int
+	query := `
+		INSERT INTO interest_groups (name, description, icon_url)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE
+		SET description = EXCLUDED.description, icon_url = EXCLUDED.icon_url
+		RETURNING id
+	`
+
+	if err := r.db.QueryRowContext(ctx, query, name, description, iconURL).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to save interest group: %w", err)
+	}
+	return id, nil
+}
+
+// IDsByName maps every interest group name to its ID.
+func (r *InterestRepository) IDsByName(ctx context.Context) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name FROM interest_groups`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interest groups: %w", err)
+	}
+	defer rows.Close()
+
+	ids := map[string]int{}
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan interest group: %w", err)
+		}
+		ids[name] = id
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *InterestRepository) AddUserInterests(ctx context.Context, userID int, interestIDs []int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	if len(interestIDs) == 0 {
+		return nil
+	}
+
+	// A single statement, so the insert is atomic without opening a
+	// transaction of its own and can run inside a unit of work.
+	query := `
+		INSERT INTO user_interests (user_id, interest_id)
+		SELECT $1, unnest($2::int[])
+		ON CONFLICT DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, pq.Array(interestIDs))
+	if foreignKeyViolation(err) {
+		return fmt.Errorf("user or interest group %w", ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add interest: %w", err)
+	}
+
+	return nil
+}
+
+// ListUserInterests returns the IDs of the groups userID has joined, in
+// ascending order.
+func (r *InterestRepository) ListUserInterests(ctx context.Context, userID int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT interest_id FROM user_interests WHERE user_id = $1 ORDER BY interest_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user interests: %w", err)
+	}
+	defer rows.Close()
+
+	return scanIDs(rows)
+}
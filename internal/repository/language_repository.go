@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"windsurf-project/internal/models"
+)
+
+// cefrRank maps a CEFR level column to 1 (A1) through 6 (C2).
+const cefrRank = `((POSITION(%s IN 'A1A2B1B2C1C2') + 1) / 2)`
+
+type LanguageRepository struct {
+	db *sql.DB
+}
+
+func NewLanguageRepository(db *sql.DB) *LanguageRepository {
+	return &LanguageRepository{db: db}
+}
+
+func (r *LanguageRepository) GetProfile(ctx context.Context, userID int) (*models.LanguageProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	profile := &models.LanguageProfile{
+		Speaks:   []models.UserLanguage{},
+		Learning: []models.UserLanguage{},
+	}
+
+	err := r.db.QueryRowContext(ctx, `SELECT timezone FROM users WHERE id = $1`, userID).Scan(&profile.Timezone)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get language profile: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT kind, language_code, level
+		FROM user_languages
+		WHERE user_id = $1
+		ORDER BY kind, language_code
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get languages: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind string
+		var lang models.UserLanguage
+		if err := rows.Scan(&kind, &lang.Language, &lang.Level); err != nil {
+			return nil, fmt.Errorf("failed to scan language: %w", err)
+		}
+		if kind == models.LanguageSpeaks {
+			profile.Speaks = append(profile.Speaks, lang)
+		} else {
+			profile.Learning = append(profile.Learning, lang)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get languages: %w", err)
+	}
+
+	return profile, nil
+}
+
+// ReplaceProfile overwrites the user's declared languages and time zone.
+func (r *LanguageRepository) ReplaceProfile(ctx context.Context, userID int, profile *models.LanguageProfile) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET timezone = $1 WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, profile.Timezone, userID); err != nil {
+		return fmt.Errorf("failed to update timezone: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_languages WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear languages: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO user_languages (user_id, kind, language_code, level) VALUES ($1, $2, $3, $4)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, lang := range profile.Speaks {
+		if _, err := stmt.ExecContext(ctx, userID, models.LanguageSpeaks, lang.Language, lang.Level); err != nil {
+			return fmt.Errorf("failed to add language: %w", err)
+		}
+	}
+	for _, lang := range profile.Learning {
+		if _, err := stmt.ExecContext(ctx, userID, models.LanguageLearning, lang.Language, lang.Level); err != nil {
+			return fmt.Errorf("failed to add language: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// FindPartners returns reciprocal tandem partners: users who speak a language
+// the caller is learning at a higher level than the caller, and who are
+// learning a language the caller speaks better than they do. Matches are
+// ranked by the size of those level gaps, shared interest groups and how
+// close the two time zones are, measured around the clock so that UTC+12
+// and UTC-11 are one hour apart. Offsets are looked up at query time, so
+// they follow daylight saving changes.
+func (r *LanguageRepository) FindPartners(ctx context.Context, userID, limit, offset int) ([]*models.LanguagePartner, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Search)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		WITH mine AS (
+			SELECT kind, language_code, level FROM user_languages WHERE user_id = $1
+		),
+		teach AS (
+			SELECT ul.user_id, ul.language_code,
+			       %[1]s - %[2]s AS gap
+			FROM user_languages ul
+			JOIN mine m ON m.language_code = ul.language_code AND m.kind = 'learning'
+			WHERE ul.kind = 'speaks' AND ul.user_id <> $1
+			  AND %[1]s > %[2]s
+		),
+		learn AS (
+			SELECT ul.user_id, ul.language_code,
+			       %[2]s - %[1]s AS gap
+			FROM user_languages ul
+			JOIN mine m ON m.language_code = ul.language_code AND m.kind = 'speaks'
+			WHERE ul.kind = 'learning' AND ul.user_id <> $1
+			  AND %[2]s > %[1]s
+		),
+		t AS (
+			SELECT user_id, ARRAY_AGG(language_code ORDER BY language_code) AS langs, SUM(gap) AS gap
+			FROM teach GROUP BY user_id
+		),
+		l AS (
+			SELECT user_id, ARRAY_AGG(language_code ORDER BY language_code) AS langs, SUM(gap) AS gap
+			FROM learn GROUP BY user_id
+		),
+		zones AS (
+			SELECT name, EXTRACT(EPOCH FROM utc_offset) / 60 AS minutes FROM pg_timezone_names
+		)
+		SELECT u.id, u.username, u.first_name, u.avatar_url, t.langs, l.langs, si.shared,
+		       (t.gap + l.gap) + 2 * si.shared +
+		       3 * COALESCE(1 - LEAST(ABS(mz.minutes - uz.minutes),
+		                              1440 - ABS(mz.minutes - uz.minutes)) / 720.0, 0) AS score
+		FROM t
+		JOIN l ON l.user_id = t.user_id
+		JOIN users u ON u.id = t.user_id AND u.is_active = TRUE AND %[3]s
+		LEFT JOIN zones uz ON uz.name = u.timezone
+		LEFT JOIN zones mz ON mz.name = (SELECT timezone FROM users WHERE id = $1)
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS shared
+			FROM user_interests a
+			JOIN user_interests b ON b.interest_id = a.interest_id
+			WHERE a.user_id = $1 AND b.user_id = u.id
+		) si
+		ORDER BY score DESC, u.id
+		LIMIT $2 OFFSET $3
+	`, fmt.Sprintf(cefrRank, "ul.level"), fmt.Sprintf(cefrRank, "m.level"), notBlocked("$1", "u.id"))
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find language partners: %w", err)
+	}
+	defer rows.Close()
+
+	partners := []*models.LanguagePartner{}
+	for rows.Next() {
+		p := &models.LanguagePartner{}
+		if err := rows.Scan(
+			&p.ID,
+			&p.Username,
+			&p.FirstName,
+			&p.AvatarURL,
+			pq.Array(&p.CanTeach),
+			pq.Array(&p.WantsToLearn),
+			&p.SharedInterests,
+			&p.Score,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan language partner: %w", err)
+		}
+		partners = append(partners, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find language partners: %w", err)
+	}
+
+	return partners, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LiveTicketRepository stores the one-time tickets that authenticate
+// WebSocket and SSE connections. Only a hash of each ticket is kept.
+type LiveTicketRepository struct {
+	db *sql.DB
+}
+
+func NewLiveTicketRepository(db *sql.DB) *LiveTicketRepository {
+	return &LiveTicketRepository{db: db}
+}
+
+// Create stores a ticket for userID that expires after ttl, and drops
+// tickets that expired unused.
+func (r *LiveTicketRepository) Create(ctx context.Context, userID int, ticketHash string, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM live_tickets WHERE expires_at <= $1`, time.Now()); err != nil {
+		return fmt.Errorf("failed to purge live tickets: %w", err)
+	}
+
+	// Expiry is set from this clock, like tokens_revoked_at, so Consume's
+	// caller can compare the two.
+	query := `INSERT INTO live_tickets (ticket_hash, user_id, expires_at) VALUES ($1, $2, $3)`
+	_, err := r.db.ExecContext(ctx, query, ticketHash, userID, time.Now().Add(ttl))
+	if foreignKeyViolation(err) {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create live ticket: %w", err)
+	}
+	return nil
+}
+
+// Consume deletes the ticket and returns its user and expiry, so a ticket
+// works once even when replicas race to redeem it.
+func (r *LiveTicketRepository) Consume(ctx context.Context, ticketHash string) (int, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		DELETE FROM live_tickets
+		WHERE ticket_hash = $1 AND expires_at > $2
+		RETURNING user_id, expires_at
+	`
+	var userID int
+	var expiresAt time.Time
+	err := r.db.QueryRowContext(ctx, query, ticketHash, time.Now()).Scan(&userID, &expiresAt)
+	if err == sql.ErrNoRows {
+		return 0, time.Time{}, fmt.Errorf("live ticket %w", ErrNotFound)
+	}
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to consume live ticket: %w", err)
+	}
+	return userID, expiresAt, nil
+}
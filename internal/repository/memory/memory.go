@@ -0,0 +1,470 @@
+// Package memory implements the repository store interfaces in memory, so
+// services can be tested without Postgres. A Store behaves like the Postgres
+// repositories, including unique and foreign key constraints, and is safe
+// for concurrent use.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+)
+
+var (
+	_ repository.UserStore     = (*Store)(nil)
+	_ repository.TokenStore    = (*Store)(nil)
+	_ repository.InterestStore = (*Store)(nil)
+	_ repository.FollowStore   = (*Store)(nil)
+	_ repository.UnitOfWork    = (*Store)(nil)
+)
+
+type user struct {
+	models.User
+	isAdmin         bool
+	isPrivate       bool
+	tokensRevokedAt *time.Time
+	lastActiveAt    *time.Time
+}
+
+type interestGroup struct {
+	id          int
+	name        string
+	description *string
+	iconURL     *string
+}
+
+// followKey is a follow edge, follower first.
+type followKey struct {
+	follower, followee int
+}
+
+// Store holds users, password reset tokens, interest groups and follows.
+type Store struct {
+	mu sync.RWMutex
+
+	users       map[int]*user
+	resetTokens map[int]*models.PasswordResetToken
+	groups      map[int]*interestGroup
+	memberships map[int]map[int]time.Time
+	follows     map[followKey]string
+
+	nextUserID  int
+	nextTokenID int
+	nextGroupID int
+
+	// version counts writes, so a unit of work can tell whether the store
+	// changed while it ran.
+	version int
+}
+
+func New() *Store {
+	return &Store{
+		users:       map[int]*user{},
+		resetTokens: map[int]*models.PasswordResetToken{},
+		groups:      map[int]*interestGroup{},
+		memberships: map[int]map[int]time.Time{},
+		follows:     map[followKey]string{},
+	}
+}
+
+func (s *Store) Create(ctx context.Context, u *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.users {
+		if existing.Email == u.Email {
+			return repository.ErrEmailTaken
+		}
+		if existing.Username == u.Username {
+			return repository.ErrUsernameTaken
+		}
+	}
+
+	s.nextUserID++
+	now := time.Now()
+	u.ID = s.nextUserID
+	u.CreatedAt = now
+	u.UpdatedAt = now
+	s.users[u.ID] = &user{User: *u}
+	s.version++
+
+	return nil
+}
+
+func (s *Store) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.Email == email {
+			found := u.User
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("user %w", repository.ErrNotFound)
+}
+
+func (s *Store) GetByID(ctx context.Context, id int) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+	found := u.User
+	return &found, nil
+}
+
+func (s *Store) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[userID]; ok {
+		u.PasswordHash = passwordHash
+		u.UpdatedAt = time.Now()
+		s.version++
+	}
+	return nil
+}
+
+func (s *Store) TouchLastActive(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[userID]; ok {
+		now := time.Now()
+		u.lastActiveAt = &now
+		s.version++
+	}
+	return nil
+}
+
+func (s *Store) GetTokenState(ctx context.Context, userID int) (bool, *time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return false, nil, fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+	return u.IsActive, u.tokensRevokedAt, nil
+}
+
+func (s *Store) Suspend(ctx context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[userID]; ok {
+		now := time.Now()
+		u.IsActive = false
+		u.tokensRevokedAt = &now
+		u.UpdatedAt = now
+		s.version++
+	}
+	return nil
+}
+
+func (s *Store) SetAdmin(ctx context.Context, userID int, isAdmin bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[userID]; ok {
+		u.isAdmin = isAdmin
+		u.UpdatedAt = time.Now()
+		s.version++
+	}
+	return nil
+}
+
+func (s *Store) IsAdmin(ctx context.Context, userID int) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[userID]
+	if !ok || !u.IsActive {
+		return false, nil
+	}
+	return u.isAdmin, nil
+}
+
+func (s *Store) UpdatePrivacy(ctx context.Context, userID int, isPrivate bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[userID]; ok {
+		u.isPrivate = isPrivate
+		u.UpdatedAt = time.Now()
+		s.version++
+	}
+	return nil
+}
+
+func (s *Store) CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[token.UserID]; !ok {
+		return fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+	for _, existing := range s.resetTokens {
+		if existing.Token == token.Token {
+			return fmt.Errorf("reset token %w", repository.ErrConflict)
+		}
+	}
+
+	s.nextTokenID++
+	token.ID = s.nextTokenID
+	token.CreatedAt = time.Now()
+	token.Used = false
+	stored := *token
+	s.resetTokens[token.ID] = &stored
+	s.version++
+
+	return nil
+}
+
+func (s *Store) GetPasswordResetToken(ctx context.Context, token string) (*models.PasswordResetToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for _, t := range s.resetTokens {
+		if t.Token == token && !t.Used && t.ExpiresAt.After(now) {
+			found := *t
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("reset token %w", repository.ErrNotFound)
+}
+
+func (s *Store) MarkTokenAsUsed(ctx context.Context, tokenID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.resetTokens[tokenID]; ok {
+		t.Used = true
+		s.version++
+	}
+	return nil
+}
+
+func (s *Store) PurgeExpiredResetTokens(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	now := time.Now()
+	for id, t := range s.resetTokens {
+		if t.Used || t.ExpiresAt.Before(now) {
+			delete(s.resetTokens, id)
+			purged++
+			s.version++
+		}
+	}
+	return purged, nil
+}
+
+func (s *Store) Upsert(ctx context.Context, name string, description, iconURL *string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, g := range s.groups {
+		if g.name == name {
+			g.description = description
+			g.iconURL = iconURL
+			s.version++
+			return g.id, nil
+		}
+	}
+
+	s.nextGroupID++
+	s.groups[s.nextGroupID] = &interestGroup{
+		id:          s.nextGroupID,
+		name:        name,
+		description: description,
+		iconURL:     iconURL,
+	}
+	s.version++
+	return s.nextGroupID, nil
+}
+
+func (s *Store) IDsByName(ctx context.Context) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make(map[string]int, len(s.groups))
+	for _, g := range s.groups {
+		ids[g.name] = g.id
+	}
+	return ids, nil
+}
+
+// AddUserInterests checks every ID before adding any, so a failed call
+// changes nothing, like the single insert of the Postgres implementation.
+func (s *Store) AddUserInterests(ctx context.Context, userID int, interestIDs []int) error {
+	if len(interestIDs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+	for _, id := range interestIDs {
+		if _, ok := s.groups[id]; !ok {
+			return fmt.Errorf("interest group %w", repository.ErrNotFound)
+		}
+	}
+
+	joined := s.memberships[userID]
+	if joined == nil {
+		joined = map[int]time.Time{}
+		s.memberships[userID] = joined
+	}
+	now := time.Now()
+	for _, id := range interestIDs {
+		if _, ok := joined[id]; !ok {
+			joined[id] = now
+			s.version++
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) ListUserInterests(ctx context.Context, userID int) ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := []int{}
+	for id := range s.memberships[userID] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (s *Store) Follow(ctx context.Context, followerID, followeeID int, status string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[followerID]; !ok {
+		return "", false, fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+	if _, ok := s.users[followeeID]; !ok {
+		return "", false, fmt.Errorf("user %w", repository.ErrNotFound)
+	}
+
+	key := followKey{followerID, followeeID}
+	if existing, ok := s.follows[key]; ok {
+		return existing, false, nil
+	}
+	s.follows[key] = status
+	s.version++
+	return status, true, nil
+}
+
+func (s *Store) GetStatus(ctx context.Context, followerID, followeeID int) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.follows[followKey{followerID, followeeID}], nil
+}
+
+func (s *Store) AcceptAllPending(ctx context.Context, followeeID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, status := range s.follows {
+		if key.followee == followeeID && status == models.FollowPending {
+			s.follows[key] = models.FollowAccepted
+			s.version++
+		}
+	}
+	return nil
+}
+
+// maxTxAttempts bounds how often Do reruns a unit of work that lost a
+// conflict, like the serialization retries of the Postgres implementation.
+const maxTxAttempts = 5
+
+// Do runs fn against a private copy of the store and publishes the copy
+// only if nothing else wrote to the store in the meantime. Otherwise fn is
+// rerun against a fresh copy, which gives units of work the same
+// serializable behavior as the Postgres implementation.
+func (s *Store) Do(ctx context.Context, fn func(stores repository.Stores) error) error {
+	for attempt := 0; attempt < maxTxAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		s.mu.RLock()
+		tx := s.clone()
+		s.mu.RUnlock()
+		base := tx.version
+
+		if err := fn(repository.Stores{Users: tx, Tokens: tx, Interests: tx, Follows: tx}); err != nil {
+			return err
+		}
+		if tx.version == base {
+			return nil
+		}
+
+		s.mu.Lock()
+		if s.version == base {
+			s.users = tx.users
+			s.resetTokens = tx.resetTokens
+			s.groups = tx.groups
+			s.memberships = tx.memberships
+			s.follows = tx.follows
+			s.nextUserID = tx.nextUserID
+			s.nextTokenID = tx.nextTokenID
+			s.nextGroupID = tx.nextGroupID
+			s.version++
+			s.mu.Unlock()
+			return nil
+		}
+		s.mu.Unlock()
+	}
+	return fmt.Errorf("transaction failed after %d attempts: concurrent update", maxTxAttempts)
+}
+
+// clone returns a deep copy of the store. The caller must hold s.mu.
+func (s *Store) clone() *Store {
+	c := New()
+	for id, u := range s.users {
+		copied := *u
+		c.users[id] = &copied
+	}
+	for id, t := range s.resetTokens {
+		copied := *t
+		c.resetTokens[id] = &copied
+	}
+	for id, g := range s.groups {
+		copied := *g
+		c.groups[id] = &copied
+	}
+	for userID, joined := range s.memberships {
+		copied := make(map[int]time.Time, len(joined))
+		for id, at := range joined {
+			copied[id] = at
+		}
+		c.memberships[userID] = copied
+	}
+	for key, status := range s.follows {
+		c.follows[key] = status
+	}
+	c.nextUserID = s.nextUserID
+	c.nextTokenID = s.nextTokenID
+	c.nextGroupID = s.nextGroupID
+	c.version = s.version
+	return c
+}
@@ -0,0 +1,19 @@
+package memory_test
+
+import (
+	"testing"
+
+	"windsurf-project/internal/repository"
+	"windsurf-project/internal/repository/memory"
+	"windsurf-project/internal/repository/repotest"
+)
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Backend {
+		store := memory.New()
+		return repotest.Backend{
+			Stores:     repository.Stores{Users: store, Tokens: store, Interests: store, Follows: store},
+			UnitOfWork: store,
+		}
+	})
+}
@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"windsurf-project/internal/models"
+)
+
+type MessageRepository struct {
+	db *sql.DB
+}
+
+func NewMessageRepository(db *sql.DB) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+// FindConversation returns the ID of the conversation between two users, or 0
+// when they have never messaged each other.
+func (r *MessageRepository) FindConversation(ctx context.Context, userA, userB int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	low, high := orderedPair(userA, userB)
+
+	var id int
+	query := `SELECT id FROM conversations WHERE user_low_id = $1 AND user_high_id = $2`
+	err := r.db.QueryRowContext(ctx, query, low, high).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find conversation: %w", err)
+	}
+	return id, nil
+}
+
+// firstContactLockClass is the first key of the transaction-level advisory
+// locks that serialize first contacts per initiator; the user ID is the
+// second.
+const firstContactLockClass = 0x66637463
+
+// ErrFirstContactQuota is returned by CreateConversation when the initiator
+// has used up their first contact quota.
+var ErrFirstContactQuota = errors.New("first contact quota reached")
+
+// FirstContactQuota caps how many conversations with strangers a member may
+// start since a given time.
+type FirstContactQuota struct {
+	Since time.Time
+	Limit int
+}
+
+// CreateConversation starts a conversation and adds both participants. If a
+// concurrent request created it first, the existing ID is returned.
+//
+// A non-nil quota marks the conversation as a first contact. The initiator's
+// earlier first contacts are counted and the conversation inserted in one
+// transaction under a lock per initiator, so parallel requests to different
+// strangers cannot all pass the limit.
+func (r *MessageRepository) CreateConversation(ctx context.Context, initiatorID, recipientID int, quota *FirstContactQuota) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	low, high := orderedPair(initiatorID, recipientID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if quota != nil {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, firstContactLockClass, initiatorID); err != nil {
+			return 0, fmt.Errorf("failed to lock first contacts: %w", err)
+		}
+
+		var count int
+		countQuery := `SELECT COUNT(*) FROM conversations WHERE initiator_id = $1 AND first_contact = TRUE AND created_at > $2`
+		if err := tx.QueryRowContext(ctx, countQuery, initiatorID, quota.Since).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to count new conversations: %w", err)
+		}
+		if count >= quota.Limit {
+			return 0, ErrFirstContactQuota
+		}
+	}
+
+	var id int
+	query := `
+		INSERT INTO conversations (user_low_id, user_high_id, initiator_id, first_contact)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_low_id, user_high_id) DO UPDATE SET user_low_id = conversations.user_low_id
+		RETURNING id
+	`
+	if err := tx.QueryRowContext(ctx, query, low, high, initiatorID, quota != nil).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	participants := `
+		INSERT INTO conversation_participants (conversation_id, user_id)
+		VALUES ($1, $2), ($1, $3)
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, participants, id, low, high); err != nil {
+		return 0, fmt.Errorf("failed to add participants: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetOtherParticipant returns the other member of a conversation, failing if
+// userID is not a participant.
+func (r *MessageRepository) GetOtherParticipant(ctx context.Context, conversationID, userID int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var otherID int
+	query := `
+		SELECT other.user_id
+		FROM conversation_participants me
+		JOIN conversation_participants other
+		  ON other.conversation_id = me.conversation_id AND other.user_id <> me.user_id
+		WHERE me.conversation_id = $1 AND me.user_id = $2
+	`
+	err := r.db.QueryRowContext(ctx, query, conversationID, userID).Scan(&otherID)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("conversation %w", ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	return otherID, nil
+}
+
+// CreateMessage stores a message, bumps the conversation and marks it read
+// for the sender.
+func (r *MessageRepository) CreateMessage(ctx context.Context, msg *models.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO messages (conversation_id, sender_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRowContext(ctx, query, msg.ConversationID, msg.SenderID, msg.Body).Scan(&msg.ID, &msg.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create message: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE conversations SET last_message_at = $1 WHERE id = $2`, msg.CreatedAt, msg.ConversationID); err != nil {
+		return fmt.Errorf("failed to update conversation: %w", err)
+	}
+
+	read := `
+		UPDATE conversation_participants SET last_read_message_id = $1, last_read_at = $2
+		WHERE conversation_id = $3 AND user_id = $4
+	`
+	if _, err := tx.ExecContext(ctx, read, msg.ID, msg.CreatedAt, msg.ConversationID, msg.SenderID); err != nil {
+		return fmt.Errorf("failed to update read state: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListMessages pages backwards through a conversation, newest first. Pass
+// beforeID 0 for the first page.
+func (r *MessageRepository) ListMessages(ctx context.Context, conversationID int, beforeID int64, limit int) ([]*models.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	args := []interface{}{conversationID, limit}
+	keyset := ""
+	if beforeID > 0 {
+		keyset = "AND id < $3"
+		args = append(args, beforeID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, conversation_id, sender_id, body, created_at
+		FROM messages
+		WHERE conversation_id = $1 %s
+		ORDER BY id DESC
+		LIMIT $2
+	`, keyset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []*models.Message{}
+	for rows.Next() {
+		m := &models.Message{}
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetLastRead returns the last message ID userID has read in a conversation.
+func (r *MessageRepository) GetLastRead(ctx context.Context, conversationID, userID int) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var lastRead int64
+	query := `SELECT last_read_message_id FROM conversation_participants WHERE conversation_id = $1 AND user_id = $2`
+	if err := r.db.QueryRowContext(ctx, query, conversationID, userID).Scan(&lastRead); err != nil {
+		return 0, fmt.Errorf("failed to get read state: %w", err)
+	}
+	return lastRead, nil
+}
+
+// MarkRead advances the user's read marker, never moving it backwards. A nil
+// upToID marks the whole conversation as read. The marker never passes the
+// latest message, so an upToID from the future cannot mark messages read
+// before they are sent.
+func (r *MessageRepository) MarkRead(ctx context.Context, conversationID, userID int, upToID *int64) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		UPDATE conversation_participants cp
+		SET last_read_message_id = GREATEST(cp.last_read_message_id,
+		        LEAST(COALESCE($1, latest.id, 0), COALESCE(latest.id, 0))),
+		    last_read_at = $3
+		FROM (SELECT MAX(id) AS id FROM messages WHERE conversation_id = $2) latest
+		WHERE cp.conversation_id = $2 AND cp.user_id = $4
+	`
+	if _, err := r.db.ExecContext(ctx, query, upToID, conversationID, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to mark conversation read: %w", err)
+	}
+	return nil
+}
+
+// UnreadCount counts messages from others the user has not read yet, across
+// all conversations with members they have not blocked.
+func (r *MessageRepository) UnreadCount(ctx context.Context, userID int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM conversation_participants cp
+		JOIN conversation_participants other
+		  ON other.conversation_id = cp.conversation_id AND other.user_id <> cp.user_id
+		JOIN messages m
+		  ON m.conversation_id = cp.conversation_id AND m.id > cp.last_read_message_id AND m.sender_id <> cp.user_id
+		WHERE cp.user_id = $1 AND ` + notBlocked("$1", "other.user_id")
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+// ListConversations pages through the user's conversations, most recently
+// active first. Conversations with blocked users are hidden.
+func (r *MessageRepository) ListConversations(ctx context.Context, userID int, beforeAt time.Time, beforeID, limit int) ([]*models.Conversation, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	args := []interface{}{userID, limit}
+	keyset := ""
+	if !beforeAt.IsZero() {
+		keyset = "AND (c.last_message_at, c.id) < ($3, $4)"
+		args = append(args, beforeAt, beforeID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT c.id, c.last_message_at, other.last_read_message_id,
+		       u.id, u.username, u.first_name, u.last_name, u.avatar_url, c.created_at,
+		       lm.id, lm.sender_id, lm.body, lm.created_at,
+		       (SELECT COUNT(*) FROM messages m
+		        WHERE m.conversation_id = c.id AND m.id > me.last_read_message_id AND m.sender_id <> $1)
+		FROM conversation_participants me
+		JOIN conversations c ON c.id = me.conversation_id
+		JOIN conversation_participants other
+		  ON other.conversation_id = c.id AND other.user_id <> me.user_id
+		JOIN users u ON u.id = other.user_id
+		LEFT JOIN LATERAL (
+			SELECT id, sender_id, body, created_at FROM messages
+			WHERE conversation_id = c.id ORDER BY id DESC LIMIT 1
+		) lm ON TRUE
+		WHERE me.user_id = $1 %s AND %s
+		ORDER BY c.last_message_at DESC, c.id DESC
+		LIMIT $2
+	`, keyset, notBlocked("$1", "u.id"))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := []*models.Conversation{}
+	for rows.Next() {
+		c := &models.Conversation{}
+		var lastID sql.NullInt64
+		var lastSender sql.NullInt64
+		var lastBody sql.NullString
+		var lastAt sql.NullTime
+		if err := rows.Scan(
+			&c.ID,
+			&c.LastMessageAt,
+			&c.OtherLastReadMessageID,
+			&c.OtherUser.ID,
+			&c.OtherUser.Username,
+			&c.OtherUser.FirstName,
+			&c.OtherUser.LastName,
+			&c.OtherUser.AvatarURL,
+			&c.OtherUser.Since,
+			&lastID,
+			&lastSender,
+			&lastBody,
+			&lastAt,
+			&c.UnreadCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		if lastID.Valid {
+			c.LastMessage = &models.Message{
+				ID:             lastID.Int64,
+				ConversationID: c.ID,
+				SenderID:       int(lastSender.Int64),
+				Body:           lastBody.String,
+				CreatedAt:      lastAt.Time,
+			}
+		}
+		conversations = append(conversations, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	return conversations, nil
+}
+
+func orderedPair(a, b int) (int, int) {
+	if a < b {
+		return a, b
+	}
+	return b, a
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"windsurf-project/internal/models"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, n *models.Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var actorID *int
+	if n.Actor != nil {
+		actorID = &n.Actor.ID
+	}
+
+	query := `
+		INSERT INTO notifications (user_id, type, actor_id, entity_type, entity_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, n.UserID, n.Type, actorID, n.EntityType, n.EntityID).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// List pages through a user's notifications, newest first. Notifications
+// from actors with a block in either direction are hidden.
+func (r *NotificationRepository) List(ctx context.Context, userID int, beforeID int64, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	args := []interface{}{userID, limit}
+	filters := ""
+	if beforeID > 0 {
+		args = append(args, beforeID)
+		filters += fmt.Sprintf(" AND n.id < $%d", len(args))
+	}
+	if unreadOnly {
+		filters += " AND n.read_at IS NULL"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT n.id, n.user_id, n.type, n.entity_type, n.entity_id, n.read_at, n.created_at,
+		       a.id, a.username, a.first_name, a.last_name, a.avatar_url
+		FROM notifications n
+		LEFT JOIN users a ON a.id = n.actor_id
+		WHERE n.user_id = $1%s AND %s
+		ORDER BY n.id DESC
+		LIMIT $2
+	`, filters, notBlocked("$1", "n.actor_id"))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n := &models.Notification{}
+		var actorID sql.NullInt64
+		var actorName sql.NullString
+		actor := &models.Connection{}
+		if err := rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Type,
+			&n.EntityType,
+			&n.EntityID,
+			&n.ReadAt,
+			&n.CreatedAt,
+			&actorID,
+			&actorName,
+			&actor.FirstName,
+			&actor.LastName,
+			&actor.AvatarURL,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		if actorID.Valid {
+			actor.ID = int(actorID.Int64)
+			actor.Username = actorName.String
+			actor.Since = n.CreatedAt
+			n.Actor = actor
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) UnreadCount(ctx context.Context, userID int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var count int
+	query := `SELECT COUNT(*) FROM notifications n WHERE n.user_id = $1 AND n.read_at IS NULL AND ` + notBlocked("$1", "n.actor_id")
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID int, id int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `UPDATE notifications SET read_at = $1 WHERE id = $2 AND user_id = $3 AND read_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, time.Now(), id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, time.Now(), userID); err != nil {
+		return fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return nil
+}
+
+// GetPreferences returns the preferences a user has explicitly set, keyed by
+// notification type.
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID int) (map[string]models.NotificationPreference, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT type, in_app, email FROM notification_preferences WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	prefs := make(map[string]models.NotificationPreference)
+	for rows.Next() {
+		var p models.NotificationPreference
+		if err := rows.Scan(&p.Type, &p.InApp, &p.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		prefs[p.Type] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+func (r *NotificationRepository) SavePreferences(ctx context.Context, userID int, prefs []models.NotificationPreference) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO notification_preferences (user_id, type, in_app, email)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, type) DO UPDATE SET in_app = EXCLUDED.in_app, email = EXCLUDED.email
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range prefs {
+		if _, err := stmt.ExecContext(ctx, userID, p.Type, p.InApp, p.Email); err != nil {
+			return fmt.Errorf("failed to save notification preference: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PresenceRepository tracks which API instances hold live connections for a
+// user, so presence is correct when clients are spread across replicas.
+// Rows that have not been refreshed within the staleness window belong to
+// instances that died and are ignored.
+type PresenceRepository struct {
+	db         *sql.DB
+	staleAfter time.Duration
+}
+
+func NewPresenceRepository(db *sql.DB, staleAfter time.Duration) *PresenceRepository {
+	return &PresenceRepository{db: db, staleAfter: staleAfter}
+}
+
+// MarkOnline records a user as connected to an instance and reports whether
+// they were already online through another instance.
+func (r *PresenceRepository) MarkOnline(ctx context.Context, userID int, instanceID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	now := time.Now()
+	query := `
+		INSERT INTO user_presence (user_id, instance_id, last_seen_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, instance_id) DO UPDATE SET last_seen_at = $3
+	`
+	if _, err := r.db.ExecContext(ctx, query, userID, instanceID, now); err != nil {
+		return false, fmt.Errorf("failed to mark user online: %w", err)
+	}
+	return r.onlineElsewhere(ctx, userID, instanceID)
+}
+
+// MarkOffline removes a user's presence on an instance and reports whether
+// they are still online through another instance.
+func (r *PresenceRepository) MarkOffline(ctx context.Context, userID int, instanceID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `DELETE FROM user_presence WHERE user_id = $1 AND instance_id = $2`
+	if _, err := r.db.ExecContext(ctx, query, userID, instanceID); err != nil {
+		return false, fmt.Errorf("failed to mark user offline: %w", err)
+	}
+	return r.onlineElsewhere(ctx, userID, instanceID)
+}
+
+// Heartbeat refreshes every presence row held by an instance and removes
+// rows left behind by instances that stopped heartbeating.
+func (r *PresenceRepository) Heartbeat(ctx context.Context, instanceID string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	now := time.Now()
+	if _, err := r.db.ExecContext(ctx, `UPDATE user_presence SET last_seen_at = $1 WHERE instance_id = $2`, now, instanceID); err != nil {
+		return fmt.Errorf("failed to refresh presence: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM user_presence WHERE last_seen_at < $1`, now.Add(-r.staleAfter)); err != nil {
+		return fmt.Errorf("failed to prune presence: %w", err)
+	}
+	return nil
+}
+
+// ClearInstance removes all presence rows of an instance on shutdown.
+func (r *PresenceRepository) ClearInstance(ctx context.Context, instanceID string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM user_presence WHERE instance_id = $1`, instanceID); err != nil {
+		return fmt.Errorf("failed to clear presence: %w", err)
+	}
+	return nil
+}
+
+// OnlineUsers returns which of userIDs are online and visible to viewerID.
+// A user's presence is visible to their conversation partners and to the
+// members whose follow they accepted, which for a private profile means
+// approved followers only. Everyone else is reported offline.
+func (r *PresenceRepository) OnlineUsers(ctx context.Context, viewerID int, userIDs []int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT p.user_id
+		FROM user_presence p
+		WHERE p.user_id = ANY($1) AND p.last_seen_at > $2 AND ` + notBlocked("$3", "p.user_id") + `
+		  AND (
+			EXISTS (
+				SELECT 1
+				FROM conversation_participants me
+				JOIN conversation_participants other
+				  ON other.conversation_id = me.conversation_id AND other.user_id = p.user_id
+				WHERE me.user_id = $3
+			)
+			OR EXISTS (
+				SELECT 1 FROM follows f
+				WHERE f.follower_id = $3 AND f.followee_id = p.user_id AND f.status = 'accepted'
+			)
+		  )`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(userIDs), time.Now().Add(-r.staleAfter), viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query presence: %w", err)
+	}
+	defer rows.Close()
+
+	return scanIDs(rows)
+}
+
+// Audience returns the users who should be told about userID's presence:
+// their conversation partners, minus anyone with a block between them.
+func (r *PresenceRepository) Audience(ctx context.Context, userID int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		SELECT other.user_id
+		FROM conversation_participants me
+		JOIN conversation_participants other
+		  ON other.conversation_id = me.conversation_id AND other.user_id <> me.user_id
+		WHERE me.user_id = $1 AND ` + notBlocked("$1", "other.user_id")
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query presence audience: %w", err)
+	}
+	defer rows.Close()
+
+	return scanIDs(rows)
+}
+
+func (r *PresenceRepository) onlineElsewhere(ctx context.Context, userID int, instanceID string) (bool, error) {
+	var online bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_presence
+			WHERE user_id = $1 AND instance_id <> $2 AND last_seen_at > $3
+		)
+	`
+	if err := r.db.QueryRowContext(ctx, query, userID, instanceID, time.Now().Add(-r.staleAfter)).Scan(&online); err != nil {
+		return false, fmt.Errorf("failed to query presence: %w", err)
+	}
+	return online, nil
+}
+
+func scanIDs(rows *sql.Rows) ([]int, error) {
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan user ids: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"windsurf-project/internal/models"
+)
+
+// recommendationLockKey identifies the Postgres advisory lock held by the
+// replica that is recomputing recommendations.
+const recommendationLockKey int64 = 0x7265636f6d6d656e
+
+type RecommendationRepository struct {
+	db *sql.DB
+}
+
+func NewRecommendationRepository(db *sql.DB) *RecommendationRepository {
+	return &RecommendationRepository{db: db}
+}
+
+// WithBatchLock runs fn if no other replica is recomputing recommendations,
+// holding an advisory lock until fn returns. It reports whether fn ran.
+func (r *RecommendationRepository) WithBatchLock(ctx context.Context, fn func() error) (bool, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection for recommendation lock: %w", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, recommendationLockKey).Scan(&locked); err != nil {
+		return false, fmt.Errorf("failed to acquire recommendation lock: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+	// Unlock even when ctx was cancelled, so the lock is not held until the
+	// pooled connection is closed.
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, recommendationLockKey)
+
+	return true, fn()
+}
+
+// ListUserIDs returns active users that have joined at least one interest
+// group, in ID order, for batch recomputation.
+func (r *RecommendationRepository) ListUserIDs(ctx context.Context, afterID, limit int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Batch)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id
+		FROM users u
+		WHERE u.is_active = TRUE AND u.id > $1
+		  AND EXISTS (SELECT 1 FROM user_interests ui WHERE ui.user_id = u.id)
+		ORDER BY u.id
+		LIMIT $2
+	`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Recompute replaces the cached recommendations for one user. Candidates are
+// scored by the Jaccard similarity of their interest groups, weighted with a
+// recency factor that decays with each week since the candidate was last
+// active.
+func (r *RecommendationRepository) Recompute(ctx context.Context, userID, keep int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Batch)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recommendations WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear recommendations: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO user_recommendations (user_id, recommended_user_id, score, shared_interests, computed_at)
+		SELECT $1, c.user_id,
+		       0.8 * c.shared::float / (mine.n + theirs.n - c.shared) +
+		       0.2 / (1 + COALESCE(EXTRACT(EPOCH FROM NOW() - u.last_active_at) / 604800, 4)),
+		       c.shared,
+		       NOW()
+		FROM (
+			SELECT b.user_id, COUNT(*) AS shared
+			FROM user_interests a
+			JOIN user_interests b ON b.interest_id = a.interest_id AND b.user_id <> a.user_id
+			WHERE a.user_id = $1
+			GROUP BY b.user_id
+		) c
+		JOIN users u ON u.id = c.user_id AND u.is_active = TRUE AND %s
+		CROSS JOIN (SELECT COUNT(*) AS n FROM user_interests WHERE user_id = $1) mine
+		CROSS JOIN LATERAL (SELECT COUNT(*) AS n FROM user_interests WHERE user_id = c.user_id) theirs
+		ORDER BY 3 DESC, c.user_id
+		LIMIT $2
+	`, notBlocked("$1", "u.id"))
+	if _, err := tx.ExecContext(ctx, query, userID, keep); err != nil {
+		return fmt.Errorf("failed to compute recommendations: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeIneligible deletes the cached recommendations of users ListUserIDs
+// no longer returns, such as members who left every interest group, which
+// a batch run would otherwise never refresh.
+func (r *RecommendationRepository) PurgeIneligible(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Batch)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM user_recommendations ur
+		WHERE NOT EXISTS (
+			SELECT 1 FROM users u
+			WHERE u.id = ur.user_id AND u.is_active = TRUE
+			  AND EXISTS (SELECT 1 FROM user_interests ui WHERE ui.user_id = u.id)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to purge recommendations: %w", err)
+	}
+	return nil
+}
+
+func (r *RecommendationRepository) List(ctx context.Context, userID, limit, offset int) ([]*models.Recommendation, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		SELECT u.id, u.username, u.first_name, u.last_name, u.avatar_url,
+		       ur.shared_interests, ur.score, ur.computed_at
+		FROM user_recommendations ur
+		JOIN users u ON u.id = ur.recommended_user_id AND u.is_active = TRUE
+		WHERE ur.user_id = $1 AND ` + notBlocked("$1", "u.id") + `
+		ORDER BY ur.score DESC, u.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recommendations: %w", err)
+	}
+	defer rows.Close()
+
+	recs := []*models.Recommendation{}
+	for rows.Next() {
+		rec := &models.Recommendation{}
+		if err := rows.Scan(
+			&rec.ID,
+			&rec.Username,
+			&rec.FirstName,
+			&rec.LastName,
+			&rec.AvatarURL,
+			&rec.SharedInterests,
+			&rec.Score,
+			&rec.ComputedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan recommendation: %w", err)
+		}
+		recs = append(recs, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list recommendations: %w", err)
+	}
+
+	return recs, nil
+}
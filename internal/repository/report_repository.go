@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"windsurf-project/internal/models"
+)
+
+const reportColumns = `id, reporter_id, target_type, target_id, reason, details, status,
+	assignee_id, resolution_note, created_at, updated_at, resolved_at`
+
+type ReportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// Create files a report. A reporter can only have one unresolved report per
+// target; a duplicate returns an error.
+func (r *ReportRepository) Create(ctx context.Context, report *models.Report) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		INSERT INTO reports (reporter_id, target_type, target_id, reason, details)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (reporter_id, target_type, target_id) WHERE status IN ('open', 'in_review') DO NOTHING
+		RETURNING id, status, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx,
+		query,
+		report.ReporterID,
+		report.TargetType,
+		report.TargetID,
+		report.Reason,
+		report.Details,
+	).Scan(&report.ID, &report.Status, &report.CreatedAt, &report.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("report %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReportRepository) GetByID(ctx context.Context, id int) (*models.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id = $1`
+
+	report, err := scanReport(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("report %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	return report, nil
+}
+
+// List returns reports with the given status, oldest first, so the queue is
+// worked in the order reports arrived.
+func (r *ReportRepository) List(ctx context.Context, status string, limit, offset int) ([]*models.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		SELECT ` + reportColumns + `
+		FROM reports
+		WHERE status = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []*models.Report{}
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+// Assign hands an unresolved report to a moderator and moves it into review.
+func (r *ReportRepository) Assign(ctx context.Context, id, assigneeID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		UPDATE reports SET assignee_id = $1, status = 'in_review', updated_at = $2
+		WHERE id = $3 AND status IN ('open', 'in_review')
+	`
+	res, err := r.db.ExecContext(ctx, query, assigneeID, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to assign report: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("report %w or already closed", ErrNotFound)
+	}
+	return nil
+}
+
+// Close marks an unresolved report as resolved or dismissed.
+func (r *ReportRepository) Close(ctx context.Context, id int, status string, note *string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	now := time.Now()
+	query := `
+		UPDATE reports SET status = $1, resolution_note = $2, resolved_at = $3, updated_at = $3
+		WHERE id = $4 AND status IN ('open', 'in_review')
+	`
+	res, err := r.db.ExecContext(ctx, query, status, note, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to close report: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("report %w or already closed", ErrNotFound)
+	}
+	return nil
+}
+
+// ErrTargetIsAdmin is returned by ApplyAction for a suspension of an
+// administrator.
+var ErrTargetIsAdmin = errors.New("administrators cannot be suspended")
+
+// ApplyAction applies a suspend or delete_content action to its target and
+// records it in one transaction, so an applied action always has its audit
+// row. Other actions are only recorded. It fails when the report has been
+// closed in the meantime, and refuses to suspend an administrator. The
+// target's row is locked before its role is read, so a concurrent role
+// change cannot slip in between the check and the suspension.
+func (r *ReportRepository) ApplyAction(ctx context.Context, action *models.ModerationAction) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var open bool
+	query := `SELECT status IN ('open', 'in_review') FROM reports WHERE id = $1 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, action.ReportID).Scan(&open)
+	if err == sql.ErrNoRows || (err == nil && !open) {
+		return fmt.Errorf("report %w or already closed", ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock report: %w", err)
+	}
+
+	var isAdmin bool
+	query = `SELECT COALESCE(is_admin, FALSE) FROM users WHERE id = $1 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, action.TargetUserID).Scan(&isAdmin)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	if isAdmin && action.Action == models.ModerationSuspend {
+		return ErrTargetIsAdmin
+	}
+
+	now := time.Now()
+	switch action.Action {
+	case models.ModerationSuspend:
+		query = `UPDATE users SET is_active = FALSE, tokens_revoked_at = $1, updated_at = $1 WHERE id = $2`
+	case models.ModerationDeleteContent:
+		query = `UPDATE users SET bio = NULL, avatar_url = NULL, updated_at = $1 WHERE id = $2`
+	default:
+		query = ""
+	}
+	if query != "" {
+		if _, err := tx.ExecContext(ctx, query, now, action.TargetUserID); err != nil {
+			return fmt.Errorf("failed to apply moderation action: %w", err)
+		}
+	}
+
+	query = `
+		INSERT INTO moderation_actions (report_id, moderator_id, target_user_id, action, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(ctx,
+		query,
+		action.ReportID,
+		action.ModeratorID,
+		action.TargetUserID,
+		action.Action,
+		action.Note,
+	).Scan(&action.ID, &action.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record moderation action: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReport(row rowScanner) (*models.Report, error) {
+	report := &models.Report{}
+	err := row.Scan(
+		&report.ID,
+		&report.ReporterID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.AssigneeID,
+		&report.ResolutionNote,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+		&report.ResolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,539 @@
+// Package repotest holds the behavioral contract for the repository store
+// interfaces. Every implementation runs the same suite, so the in-memory
+// stores used in service tests cannot drift from Postgres.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+)
+
+// Backend is one implementation of the store interfaces and the unit of
+// work over them. All of them must share the same underlying data.
+type Backend struct {
+	repository.Stores
+	UnitOfWork repository.UnitOfWork
+}
+
+// Run runs the contract against the backend returned by open, which is
+// called once per subtest and must return empty stores.
+//
+// Expiry times are days away from now, so the suite does not depend on the
+// database and the test process agreeing on a time zone.
+func Run(t *testing.T, open func(t *testing.T) Backend) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, open(t)) })
+	t.Run("UserUniqueness", func(t *testing.T) { testUserUniqueness(t, open(t)) })
+	t.Run("Suspend", func(t *testing.T) { testSuspend(t, open(t)) })
+	t.Run("Admin", func(t *testing.T) { testAdmin(t, open(t)) })
+	t.Run("ResetTokens", func(t *testing.T) { testResetTokens(t, open(t)) })
+	t.Run("PurgeResetTokens", func(t *testing.T) { testPurgeResetTokens(t, open(t)) })
+	t.Run("Interests", func(t *testing.T) { testInterests(t, open(t)) })
+	t.Run("Follows", func(t *testing.T) { testFollows(t, open(t)) })
+	t.Run("ConcurrentCreate", func(t *testing.T) { testConcurrentCreate(t, open(t)) })
+	t.Run("UnitOfWorkCommits", func(t *testing.T) { testUnitOfWorkCommits(t, open(t)) })
+	t.Run("UnitOfWorkRollsBack", func(t *testing.T) { testUnitOfWorkRollsBack(t, open(t)) })
+	t.Run("UnitOfWorkSerializes", func(t *testing.T) { testUnitOfWorkSerializes(t, open(t)) })
+}
+
+func newUser(t *testing.T, s Backend, name string) *models.User {
+	t.Helper()
+
+	u := &models.User{
+		Email:        name + "@example.com",
+		Username:     name,
+		PasswordHash: "hash-" + name,
+		IsActive:     true,
+	}
+	if err := s.Users.Create(context.Background(), u); err != nil {
+		t.Fatalf("Create(%s): %v", name, err)
+	}
+	return u
+}
+
+func testUsers(t *testing.T, s Backend) {
+	ctx := context.Background()
+
+	first := "Ada"
+	u := &models.User{
+		Email:        "ada@example.com",
+		Username:     "ada",
+		PasswordHash: "hash",
+		FirstName:    &first,
+		IsActive:     true,
+	}
+	if err := s.Users.Create(ctx, u); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if u.ID == 0 || u.CreatedAt.IsZero() || u.UpdatedAt.IsZero() {
+		t.Fatalf("Create did not fill in ID and timestamps: %+v", u)
+	}
+
+	byEmail, err := s.Users.GetByEmail(ctx, "ada@example.com")
+	if err != nil {
+		t.Fatalf("GetByEmail: %v", err)
+	}
+	if byEmail.ID != u.ID || byEmail.Username != "ada" || byEmail.PasswordHash != "hash" {
+		t.Errorf("GetByEmail = %+v, want user %d", byEmail, u.ID)
+	}
+	if byEmail.FirstName == nil || *byEmail.FirstName != "Ada" {
+		t.Errorf("GetByEmail first name = %v, want Ada", byEmail.FirstName)
+	}
+
+	byID, err := s.Users.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if byID.Email != "ada@example.com" || !byID.IsActive {
+		t.Errorf("GetByID = %+v", byID)
+	}
+
+	if _, err := s.Users.GetByEmail(ctx, "nobody@example.com"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetByEmail of an unknown email = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Users.GetByID(ctx, u.ID+1000); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetByID of an unknown ID = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Users.UpdatePassword(ctx, u.ID, "new-hash"); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+	updated, err := s.Users.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if updated.PasswordHash != "new-hash" {
+		t.Errorf("password hash = %q, want new-hash", updated.PasswordHash)
+	}
+
+	if err := s.Users.TouchLastActive(ctx, u.ID); err != nil {
+		t.Errorf("TouchLastActive: %v", err)
+	}
+}
+
+func testUserUniqueness(t *testing.T, s Backend) {
+	ctx := context.Background()
+	newUser(t, s, "grace")
+
+	sameEmail := &models.User{Email: "grace@example.com", Username: "other", PasswordHash: "x", IsActive: true}
+	if err := s.Users.Create(ctx, sameEmail); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Create with a duplicate email = %v, want ErrConflict", err)
+	}
+
+	sameUsername := &models.User{Email: "other@example.com", Username: "grace", PasswordHash: "x", IsActive: true}
+	if err := s.Users.Create(ctx, sameUsername); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Create with a duplicate username = %v, want ErrConflict", err)
+	}
+}
+
+func testSuspend(t *testing.T, s Backend) {
+	ctx := context.Background()
+	u := newUser(t, s, "linus")
+
+	active, revokedAt, err := s.Users.GetTokenState(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("GetTokenState: %v", err)
+	}
+	if !active || revokedAt != nil {
+		t.Fatalf("new user token state = %v, %v; want active and never revoked", active, revokedAt)
+	}
+
+	if err := s.Users.Suspend(ctx, u.ID); err != nil {
+		t.Fatalf("Suspend: %v", err)
+	}
+
+	active, revokedAt, err = s.Users.GetTokenState(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("GetTokenState: %v", err)
+	}
+	if active {
+		t.Error("suspended user is still active")
+	}
+	if revokedAt == nil {
+		t.Error("suspending did not revoke tokens")
+	}
+
+	if _, _, err := s.Users.GetTokenState(ctx, u.ID+1000); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetTokenState of an unknown user = %v, want ErrNotFound", err)
+	}
+}
+
+func testAdmin(t *testing.T, s Backend) {
+	ctx := context.Background()
+	u := newUser(t, s, "margaret")
+
+	assertAdmin := func(userID int, want bool) {
+		t.Helper()
+		got, err := s.Users.IsAdmin(ctx, userID)
+		if err != nil {
+			t.Fatalf("IsAdmin: %v", err)
+		}
+		if got != want {
+			t.Errorf("IsAdmin(%d) = %v, want %v", userID, got, want)
+		}
+	}
+
+	assertAdmin(u.ID, false)
+	assertAdmin(u.ID+1000, false)
+
+	if err := s.Users.SetAdmin(ctx, u.ID, true); err != nil {
+		t.Fatalf("SetAdmin: %v", err)
+	}
+	assertAdmin(u.ID, true)
+
+	if err := s.Users.Suspend(ctx, u.ID); err != nil {
+		t.Fatalf("Suspend: %v", err)
+	}
+	assertAdmin(u.ID, false)
+}
+
+func testResetTokens(t *testing.T, s Backend) {
+	ctx := context.Background()
+	u := newUser(t, s, "barbara")
+
+	token := &models.PasswordResetToken{UserID: u.ID, Token: "valid", ExpiresAt: time.Now().Add(48 * time.Hour)}
+	if err := s.Tokens.CreatePasswordResetToken(ctx, token); err != nil {
+		t.Fatalf("CreatePasswordResetToken: %v", err)
+	}
+	if token.ID == 0 || token.CreatedAt.IsZero() {
+		t.Fatalf("CreatePasswordResetToken did not fill in ID and creation time: %+v", token)
+	}
+
+	found, err := s.Tokens.GetPasswordResetToken(ctx, "valid")
+	if err != nil {
+		t.Fatalf("GetPasswordResetToken: %v", err)
+	}
+	if found.ID != token.ID || found.UserID != u.ID || found.Used {
+		t.Errorf("GetPasswordResetToken = %+v, want unused token %d", found, token.ID)
+	}
+
+	duplicate := &models.PasswordResetToken{UserID: u.ID, Token: "valid", ExpiresAt: time.Now().Add(48 * time.Hour)}
+	if err := s.Tokens.CreatePasswordResetToken(ctx, duplicate); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("CreatePasswordResetToken with a duplicate token = %v, want ErrConflict", err)
+	}
+
+	orphan := &models.PasswordResetToken{UserID: u.ID + 1000, Token: "orphan", ExpiresAt: time.Now().Add(48 * time.Hour)}
+	if err := s.Tokens.CreatePasswordResetToken(ctx, orphan); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("CreatePasswordResetToken for an unknown user = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Tokens.MarkTokenAsUsed(ctx, token.ID); err != nil {
+		t.Fatalf("MarkTokenAsUsed: %v", err)
+	}
+	if _, err := s.Tokens.GetPasswordResetToken(ctx, "valid"); err == nil {
+		t.Error("GetPasswordResetToken returned a used token")
+	}
+
+	expired := &models.PasswordResetToken{UserID: u.ID, Token: "expired", ExpiresAt: time.Now().Add(-48 * time.Hour)}
+	if err := s.Tokens.CreatePasswordResetToken(ctx, expired); err != nil {
+		t.Fatalf("CreatePasswordResetToken: %v", err)
+	}
+	if _, err := s.Tokens.GetPasswordResetToken(ctx, "expired"); err == nil {
+		t.Error("GetPasswordResetToken returned an expired token")
+	}
+
+	if _, err := s.Tokens.GetPasswordResetToken(ctx, "unknown"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("GetPasswordResetToken of an unknown token = %v, want ErrNotFound", err)
+	}
+}
+
+func testPurgeResetTokens(t *testing.T, s Backend) {
+	ctx := context.Background()
+	u := newUser(t, s, "edsger")
+
+	tokens := []*models.PasswordResetToken{
+		{UserID: u.ID, Token: "live", ExpiresAt: time.Now().Add(48 * time.Hour)},
+		{UserID: u.ID, Token: "used", ExpiresAt: time.Now().Add(48 * time.Hour)},
+		{UserID: u.ID, Token: "expired", ExpiresAt: time.Now().Add(-48 * time.Hour)},
+	}
+	for _, token := range tokens {
+		if err := s.Tokens.CreatePasswordResetToken(ctx, token); err != nil {
+			t.Fatalf("CreatePasswordResetToken(%s): %v", token.Token, err)
+		}
+	}
+	if err := s.Tokens.MarkTokenAsUsed(ctx, tokens[1].ID); err != nil {
+		t.Fatalf("MarkTokenAsUsed: %v", err)
+	}
+
+	purged, err := s.Tokens.PurgeExpiredResetTokens(ctx)
+	if err != nil {
+		t.Fatalf("PurgeExpiredResetTokens: %v", err)
+	}
+	if purged != 2 {
+		t.Errorf("purged %d tokens, want 2", purged)
+	}
+	if _, err := s.Tokens.GetPasswordResetToken(ctx, "live"); err != nil {
+		t.Errorf("live token was purged: %v", err)
+	}
+}
+
+func testInterests(t *testing.T, s Backend) {
+	ctx := context.Background()
+	u := newUser(t, s, "alan")
+
+	description := "Remote workers"
+	coworking, err := s.Interests.Upsert(ctx, "Coworking", &description, nil)
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	hiking, err := s.Interests.Upsert(ctx, "Hiking", nil, nil)
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	again, err := s.Interests.Upsert(ctx, "Coworking", nil, nil)
+	if err != nil {
+		t.Fatalf("Upsert of an existing name: %v", err)
+	}
+	if again != coworking {
+		t.Errorf("Upsert of an existing name returned %d, want %d", again, coworking)
+	}
+
+	ids, err := s.Interests.IDsByName(ctx)
+	if err != nil {
+		t.Fatalf("IDsByName: %v", err)
+	}
+	want := map[string]int{"Coworking": coworking, "Hiking": hiking}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("IDsByName = %v, want %v", ids, want)
+	}
+
+	if err := s.Interests.AddUserInterests(ctx, u.ID, []int{hiking, coworking}); err != nil {
+		t.Fatalf("AddUserInterests: %v", err)
+	}
+	if err := s.Interests.AddUserInterests(ctx, u.ID, []int{hiking}); err != nil {
+		t.Errorf("AddUserInterests of a joined group: %v", err)
+	}
+	if err := s.Interests.AddUserInterests(ctx, u.ID, nil); err != nil {
+		t.Errorf("AddUserInterests with no groups: %v", err)
+	}
+
+	unknown := coworking + hiking + 1000
+	if err := s.Interests.AddUserInterests(ctx, u.ID, []int{unknown}); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("AddUserInterests with an unknown group = %v, want ErrNotFound", err)
+	}
+
+	joined, err := s.Interests.ListUserInterests(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("ListUserInterests: %v", err)
+	}
+	wantJoined := []int{coworking, hiking}
+	if hiking < coworking {
+		wantJoined = []int{hiking, coworking}
+	}
+	if !reflect.DeepEqual(joined, wantJoined) {
+		t.Errorf("ListUserInterests = %v, want %v", joined, wantJoined)
+	}
+
+	none, err := s.Interests.ListUserInterests(ctx, u.ID+1000)
+	if err != nil {
+		t.Fatalf("ListUserInterests: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("ListUserInterests of an unknown user = %v, want none", none)
+	}
+}
+
+func testConcurrentCreate(t *testing.T, s Backend) {
+	ctx := context.Background()
+
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every other user reuses the previous username, so exactly
+			// half of the creates must fail.
+			name := fmt.Sprintf("user%d", i/2)
+			errs <- s.Users.Create(ctx, &models.User{
+				Email:        fmt.Sprintf("user%d@example.com", i),
+				Username:     name,
+				PasswordHash: "x",
+				IsActive:     true,
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed != n/2 {
+		t.Errorf("%d concurrent creates failed, want %d", failed, n/2)
+	}
+}
+
+func testUnitOfWorkCommits(t *testing.T, s Backend) {
+	ctx := context.Background()
+
+	var created *models.User
+	err := s.UnitOfWork.Do(ctx, func(tx repository.Stores) error {
+		created = &models.User{Email: "ken@example.com", Username: "ken", PasswordHash: "x", IsActive: true}
+		if err := tx.Users.Create(ctx, created); err != nil {
+			return err
+		}
+		// Writes are visible to later calls in the same unit of work.
+		_, err := tx.Users.GetByEmail(ctx, "ken@example.com")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	u, err := s.Users.GetByEmail(ctx, "ken@example.com")
+	if err != nil {
+		t.Fatalf("committed user not found: %v", err)
+	}
+	if u.ID != created.ID {
+		t.Errorf("committed user has ID %d, want %d", u.ID, created.ID)
+	}
+}
+
+func testUnitOfWorkRollsBack(t *testing.T, s Backend) {
+	ctx := context.Background()
+	u := newUser(t, s, "dennis")
+
+	failure := errors.New("fail after writing")
+	err := s.UnitOfWork.Do(ctx, func(tx repository.Stores) error {
+		if err := tx.Users.UpdatePassword(ctx, u.ID, "changed"); err != nil {
+			return err
+		}
+		other := &models.User{Email: "rob@example.com", Username: "rob", PasswordHash: "x", IsActive: true}
+		if err := tx.Users.Create(ctx, other); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Do = %v, want the error returned by fn", err)
+	}
+
+	unchanged, err := s.Users.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.PasswordHash != u.PasswordHash {
+		t.Errorf("password hash = %q after rollback, want %q", unchanged.PasswordHash, u.PasswordHash)
+	}
+	if _, err := s.Users.GetByEmail(ctx, "rob@example.com"); err == nil {
+		t.Error("user created in a rolled back unit of work exists")
+	}
+}
+
+func testFollows(t *testing.T, s Backend) {
+	ctx := context.Background()
+	owner := newUser(t, s, "grace")
+	fan := newUser(t, s, "linus")
+	friend := newUser(t, s, "ada")
+
+	if status, created, err := s.Follows.Follow(ctx, fan.ID, owner.ID, models.FollowPending); err != nil || !created || status != models.FollowPending {
+		t.Fatalf("Follow = %q, %v, %v, want a new pending edge", status, created, err)
+	}
+	if status, created, err := s.Follows.Follow(ctx, fan.ID, owner.ID, models.FollowAccepted); err != nil || created || status != models.FollowPending {
+		t.Errorf("second Follow = %q, %v, %v, want the existing pending edge", status, created, err)
+	}
+	if _, _, err := s.Follows.Follow(ctx, friend.ID, owner.ID, models.FollowPending); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := s.Follows.GetStatus(ctx, owner.ID, fan.ID); err != nil || status != "" {
+		t.Errorf("GetStatus of a missing edge = %q, %v, want \"\"", status, err)
+	}
+
+	// A failed unit of work leaves the requests pending.
+	failure := errors.New("fail after accepting")
+	err := s.UnitOfWork.Do(ctx, func(tx repository.Stores) error {
+		if err := tx.Users.UpdatePrivacy(ctx, owner.ID, false); err != nil {
+			return err
+		}
+		if err := tx.Follows.AcceptAllPending(ctx, owner.ID); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Do = %v, want the error returned by fn", err)
+	}
+	if status, err := s.Follows.GetStatus(ctx, fan.ID, owner.ID); err != nil || status != models.FollowPending {
+		t.Errorf("status after rollback = %q, %v, want pending", status, err)
+	}
+
+	err = s.UnitOfWork.Do(ctx, func(tx repository.Stores) error {
+		if err := tx.Users.UpdatePrivacy(ctx, owner.ID, false); err != nil {
+			return err
+		}
+		return tx.Follows.AcceptAllPending(ctx, owner.ID)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []*models.User{fan, friend} {
+		if status, err := s.Follows.GetStatus(ctx, u.ID, owner.ID); err != nil || status != models.FollowAccepted {
+			t.Errorf("status of %s after commit = %q, %v, want accepted", u.Username, status, err)
+		}
+	}
+}
+
+func testUnitOfWorkSerializes(t *testing.T, s Backend) {
+	ctx := context.Background()
+	u := newUser(t, s, "bjarne")
+
+	token := &models.PasswordResetToken{UserID: u.ID, Token: "once", ExpiresAt: time.Now().Add(48 * time.Hour)}
+	if err := s.Tokens.CreatePasswordResetToken(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every worker tries to consume the same token. Each unit of work reads
+	// the token and then marks it used, so without serialization several
+	// of them could see it unused.
+	const n = 8
+	var wg sync.WaitGroup
+	consumed := make(chan bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			used := false
+			err := s.UnitOfWork.Do(ctx, func(tx repository.Stores) error {
+				used = false
+				found, err := tx.Tokens.GetPasswordResetToken(ctx, "once")
+				if err != nil {
+					return nil
+				}
+				if err := tx.Tokens.MarkTokenAsUsed(ctx, found.ID); err != nil {
+					return err
+				}
+				used = true
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Do: %v", err)
+			}
+			consumed <- err == nil && used
+		}()
+	}
+	wg.Wait()
+	close(consumed)
+
+	count := 0
+	for ok := range consumed {
+		if ok {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("token consumed %d times, want 1", count)
+	}
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"windsurf-project/internal/models"
+)
+
+// Search snippets mark matched words with these control characters, which
+// are stripped from the text beforehand so that only ts_headline can
+// produce them. The service turns them into markup after escaping the text.
+const (
+	HighlightStart = "\x02"
+	HighlightStop  = "\x03"
+)
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// searchHits is a CTE of every member and group matching $1 for viewer $2.
+// A row matches on its tsvector, built from every supported language so the
+// query is stemmed the same way as the text, or by trigram similarity of its
+// name, which tolerates typos. Names (weight A) rank above bodies (weight B).
+const searchHits = `
+	q AS (SELECT %[1]s AS query),
+	hits AS (
+		SELECT 'member' AS type, u.id, u.username AS title,
+		       NULLIF(TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')), '') AS subtitle,
+		       u.avatar_url AS image_url, COALESCE(u.bio, '') AS body,
+		       ts_rank_cd(u.search_vector, q.query) + similarity(u.username, $1) AS rank
+		FROM users u, q
+		WHERE u.is_active = TRUE AND u.id <> $2 AND %[2]s
+		  AND (u.search_vector @@ q.query OR u.username %% $1)
+		UNION ALL
+		SELECT 'group', g.id, g.name, NULL, g.icon_url, COALESCE(g.description, ''),
+		       ts_rank_cd(g.search_vector, q.query) + similarity(g.name, $1)
+		FROM interest_groups g, q
+		WHERE g.search_vector @@ q.query OR g.name %% $1
+	)`
+
+// tsQuery builds the query for $1 in the simple configuration, which matches
+// names verbatim, plus the stemmed form for config, or for every supported
+// language when config is empty.
+func tsQuery(config string) string {
+	configs := []string{"simple"}
+	if config != "" {
+		configs = append(configs, config)
+	} else {
+		for _, lang := range []string{"en", "es", "fr", "de"} {
+			configs = append(configs, models.SearchLanguages[lang])
+		}
+	}
+
+	parts := make([]string, len(configs))
+	for i, c := range configs {
+		parts[i] = fmt.Sprintf("websearch_to_tsquery('%s', $1)", c)
+	}
+	return strings.Join(parts, " || ")
+}
+
+// Search returns one page of hits for text, best first, optionally restricted
+// to one result type. config must be a value of models.SearchLanguages or
+// empty.
+func (r *SearchRepository) Search(ctx context.Context, viewerID int, text, config, resultType string, limit, offset int) ([]*models.SearchResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Search)
+	defer cancel()
+
+	headlineConfig := config
+	if headlineConfig == "" {
+		headlineConfig = "simple"
+	}
+
+	query := fmt.Sprintf(`WITH `+searchHits+`
+		SELECT h.type, h.id, h.title, h.subtitle, h.image_url,
+		       ts_headline('%[3]s', translate(h.body, chr(2) || chr(3), ''), q.query,
+		                   'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2, MaxWords=20, MinWords=5'),
+		       h.rank
+		FROM hits h, q
+		WHERE ($3 = '' OR h.type = $3)
+		ORDER BY h.rank DESC, h.type, h.id
+		LIMIT $4 OFFSET $5
+	`, tsQuery(config), notBlocked("$2", "u.id"), headlineConfig)
+
+	rows, err := r.db.QueryContext(ctx, query, text, viewerID, resultType, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*models.SearchResult{}
+	for rows.Next() {
+		res := &models.SearchResult{}
+		if err := rows.Scan(&res.Type, &res.ID, &res.Title, &res.Subtitle, &res.ImageURL, &res.Snippet, &res.Rank); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	return results, nil
+}
+
+// Facets counts all hits for text by result type.
+func (r *SearchRepository) Facets(ctx context.Context, viewerID int, text, config string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Search)
+	defer cancel()
+
+	query := fmt.Sprintf(`WITH `+searchHits+`
+		SELECT type, COUNT(*) FROM hits GROUP BY type
+	`, tsQuery(config), notBlocked("$2", "u.id"))
+
+	rows, err := r.db.QueryContext(ctx, query, text, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+	defer rows.Close()
+
+	facets := map[string]int{models.SearchTypeMember: 0, models.SearchTypeGroup: 0}
+	for rows.Next() {
+		var resultType string
+		var count int
+		if err := rows.Scan(&resultType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan search facet: %w", err)
+		}
+		facets[resultType] = count
+	}
+
+	return facets, rows.Err()
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"windsurf-project/internal/models"
+)
+
+// UserStore is the account storage used by authentication. UserRepository
+// implements it on Postgres and memory.Store in memory for tests.
+type UserStore interface {
+	// Create inserts user and fills in its ID and timestamps. Emails and
+	// usernames are unique.
+	Create(ctx context.Context, user *models.User) error
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByID(ctx context.Context, id int) (*models.User, error)
+	UpdatePassword(ctx context.Context, userID int, passwordHash string) error
+	TouchLastActive(ctx context.Context, userID int) error
+	// GetTokenState reports whether the account is active and when its
+	// tokens were last revoked, if ever.
+	GetTokenState(ctx context.Context, userID int) (bool, *time.Time, error)
+	// Suspend deactivates the account and revokes every token issued so far.
+	Suspend(ctx context.Context, userID int) error
+	SetAdmin(ctx context.Context, userID int, isAdmin bool) error
+	// IsAdmin is false for unknown and deactivated users.
+	IsAdmin(ctx context.Context, userID int) (bool, error)
+	UpdatePrivacy(ctx context.Context, userID int, isPrivate bool) error
+}
+
+// FollowStore holds the follower graph.
+type FollowStore interface {
+	// Follow creates an edge with the given status and returns the status
+	// the edge ends up with and whether it was created. An existing edge is
+	// left untouched.
+	Follow(ctx context.Context, followerID, followeeID int, status string) (string, bool, error)
+	// GetStatus returns the status of the edge, or "" when there is none.
+	GetStatus(ctx context.Context, followerID, followeeID int) (string, error)
+	// AcceptAllPending accepts every pending request to followeeID.
+	AcceptAllPending(ctx context.Context, followeeID int) error
+}
+
+// TokenStore holds password reset tokens.
+type TokenStore interface {
+	// CreatePasswordResetToken inserts token and fills in its ID and
+	// creation time.
+	CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	// GetPasswordResetToken returns the token only while it is unused and
+	// not expired.
+	GetPasswordResetToken(ctx context.Context, token string) (*models.PasswordResetToken, error)
+	MarkTokenAsUsed(ctx context.Context, tokenID int) error
+	// PurgeExpiredResetTokens deletes used and expired tokens and returns
+	// how many were removed.
+	PurgeExpiredResetTokens(ctx context.Context) (int64, error)
+}
+
+// InterestStore holds interest groups and their members.
+type InterestStore interface {
+	// Upsert creates the group or updates the existing group with that
+	// name, and returns its ID.
+	Upsert(ctx context.Context, name string, description, iconURL *string) (int, error)
+	IDsByName(ctx context.Context) (map[string]int, error)
+	// AddUserInterests joins userID to the groups. Joining a group twice is
+	// not an error.
+	AddUserInterests(ctx context.Context, userID int, interestIDs []int) error
+	ListUserInterests(ctx context.Context, userID int) ([]int, error)
+}
+
+var (
+	_ UserStore     = (*UserRepository)(nil)
+	_ TokenStore    = (*TokenRepository)(nil)
+	_ InterestStore = (*InterestRepository)(nil)
+	_ FollowStore   = (*FollowRepository)(nil)
+)
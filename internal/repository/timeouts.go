@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Timeouts bounds how long a single repository operation may run. Each
+// method derives its deadline from the caller's context, so a request that
+// is cancelled or already close to its own deadline stops earlier.
+type Timeouts struct {
+	// Default applies to ordinary reads and writes.
+	Default time.Duration
+	// Search applies to full-text and geographic lookups.
+	Search time.Duration
+	// Batch applies to background jobs that scan or rewrite many rows.
+	Batch time.Duration
+}
+
+var timeouts = Timeouts{
+	Default: 5 * time.Second,
+	Search:  10 * time.Second,
+	Batch:   2 * time.Minute,
+}
+
+// ConfigureTimeouts replaces the operation timeouts. Zero fields keep their
+// current value. It must be called before the repositories are used.
+func ConfigureTimeouts(t Timeouts) {
+	if t.Default > 0 {
+		timeouts.Default = t.Default
+	}
+	if t.Search > 0 {
+		timeouts.Search = t.Search
+	}
+	if t.Batch > 0 {
+		timeouts.Batch = t.Batch
+	}
+}
+
+// IsTimeout reports whether err means a query ran out of time, either
+// because its context deadline passed or because Postgres cancelled the
+// statement.
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "57014"
+}
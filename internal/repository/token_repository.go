@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"windsurf-project/internal/models"
+)
+
+// TokenRepository stores password reset tokens.
+type TokenRepository struct {
+	db dbtx
+}
+
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+func (r *TokenRepository) CreatePasswordResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, token.UserID, token.Token, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+	if _, ok := uniqueViolation(err); ok {
+		return fmt.Errorf("reset token %w", ErrConflict)
+	}
+	if foreignKeyViolation(err) {
+		return fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TokenRepository) GetPasswordResetToken(ctx context.Context, token string) (*models.PasswordResetToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	resetToken := &models.PasswordResetToken{}
+	query := `
+		SELECT id, user_id, token, expires_at, used, created_at
+		FROM password_reset_tokens
+		WHERE token = $1 AND used = FALSE AND expires_at > NOW()
+	`
+
+	err := r.db.QueryRowContext(ctx, query, token).Scan(
+		&resetToken.ID,
+		&resetToken.UserID,
+		&resetToken.Token,
+		&resetToken.ExpiresAt,
+		&resetToken.Used,
+		&resetToken.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("reset token %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+	}
+
+	return resetToken, nil
+}
+
+func (r *TokenRepository) MarkTokenAsUsed(ctx context.Context, tokenID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `UPDATE password_reset_tokens SET used = TRUE WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to mark token as used: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredResetTokens deletes password reset tokens that are used or
+// expired and returns how many were removed.
+func (r *TokenRepository) PurgeExpiredResetTokens(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Batch)
+	defer cancel()
+
+	query := `DELETE FROM password_reset_tokens WHERE used = TRUE OR expires_at < $1`
+	result, err := r.db.ExecContext(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge reset tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
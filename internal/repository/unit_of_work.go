@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// dbtx is what repositories need from a connection, satisfied by both
+// *sql.DB and *sql.Tx so the same repository code runs inside a unit of work.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Stores are the stores available inside a unit of work.
+type Stores struct {
+	Users     UserStore
+	Tokens    TokenStore
+	Interests InterestStore
+	Follows   FollowStore
+}
+
+// UnitOfWork runs several store calls atomically.
+type UnitOfWork interface {
+	// Do calls fn with stores bound to one transaction and commits when fn
+	// returns nil. If the transaction loses a serialization conflict, fn may
+	// be called again, so it must not have side effects outside the stores.
+	Do(ctx context.Context, fn func(stores Stores) error) error
+}
+
+// maxTxAttempts bounds how often a unit of work is retried after a
+// serialization failure.
+const maxTxAttempts = 5
+
+// PostgresUnitOfWork runs units of work in serializable transactions.
+type PostgresUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewUnitOfWork(db *sql.DB) *PostgresUnitOfWork {
+	return &PostgresUnitOfWork{db: db}
+}
+
+var _ UnitOfWork = (*PostgresUnitOfWork)(nil)
+
+func (u *PostgresUnitOfWork) Do(ctx context.Context, fn func(stores Stores) error) error {
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = u.try(ctx, fn)
+		if !IsSerializationFailure(err) {
+			return err
+		}
+
+		// Back off a little longer each time so that the conflicting
+		// transactions do not collide again straight away.
+		select {
+		case <-time.After(time.Duration(attempt) * 10 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return fmt.Errorf("transaction failed after %d attempts: %w", maxTxAttempts, err)
+}
+
+func (u *PostgresUnitOfWork) try(ctx context.Context, fn func(stores Stores) error) error {
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stores := Stores{
+		Users:     &UserRepository{db: tx},
+		Tokens:    &TokenRepository{db: tx},
+		Interests: &InterestRepository{db: tx},
+		Follows:   &FollowRepository{db: tx},
+	}
+	if err := fn(stores); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// IsSerializationFailure reports whether err is a Postgres serialization
+// failure or deadlock, after which the whole transaction can be retried.
+func IsSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
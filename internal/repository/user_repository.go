@@ -1,29 +1,37 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"math"
 	"time"
 
 	"windsurf-project/internal/models"
 )
 
+// kmPerDegree is the approximate length of one degree of latitude.
+const kmPerDegree = 111.045
+
 type UserRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) Create(user *models.User) error {
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
 	query := `
 		INSERT INTO users (email, username, password_hash, first_name, last_name, is_verified, is_active)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, updated_at
 	`
 	
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(ctx,
 		query,
 		user.Email,
 		user.Username,
@@ -34,6 +42,14 @@ func (r *UserRepository) Create(user *models.User) error {
 		user.IsActive,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
+	if constraint, ok := uniqueViolation(err); ok {
+		switch constraint {
+		case "users_email_key":
+			return ErrEmailTaken
+		case "users_username_key":
+			return ErrUsernameTaken
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -41,7 +57,10 @@ func (r *UserRepository) Create(user *models.User) error {
 	return nil
 }
 
-func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
 	user := &models.User{}
 	query := `
 		SELECT id, email, username, password_hash, first_name, last_name, bio, 
@@ -50,7 +69,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 		WHERE email = $1
 	`
 
-	err := r.db.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Username,
@@ -66,7 +85,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -75,7 +94,10 @@ func (r *UserRepository) GetByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) GetByID(id int) (*models.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
 	user := &models.User{}
 	query := `
 		SELECT id, email, username, password_hash, first_name, last_name, bio, 
@@ -84,7 +106,7 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 		WHERE id = $1
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Username,
@@ -100,7 +122,7 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -109,92 +131,285 @@ func (r *UserRepository) GetByID(id int) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) UpdatePassword(userID int, passwordHash string) error {
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
 	query := `UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3`
-	_, err := r.db.Exec(query, passwordHash, time.Now(), userID)
+	_, err := r.db.ExecContext(ctx, query, passwordHash, time.Now(), userID)
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 	return nil
 }
 
-func (r *UserRepository) AddUserInterests(userID int, interestIDs []int) error {
-	if len(interestIDs) == 0 {
-		return nil
+func (r *UserRepository) UpdateLocation(ctx context.Context, userID int, loc *models.UserLocation) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET city = $1, latitude = $2, longitude = $3, share_location = $4, updated_at = $5
+		WHERE id = $6
+	`
+	_, err := r.db.ExecContext(ctx, query, loc.City, loc.Latitude, loc.Longitude, loc.ShareLocation, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update location: %w", err)
 	}
+	return nil
+}
+
+func (r *UserRepository) GetLocation(ctx context.Context, userID int) (*models.UserLocation, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
 
-	tx, err := r.db.Begin()
+	loc := &models.UserLocation{}
+	query := `SELECT city, latitude, longitude, COALESCE(share_location, FALSE) FROM users WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&loc.City, &loc.Latitude, &loc.Longitude, &loc.ShareLocation)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to get location: %w", err)
 	}
-	defer tx.Rollback()
 
-	stmt, err := tx.Prepare("INSERT INTO user_interests (user_id, interest_id) VALUES ($1, $2) ON CONFLICT DO NOTHING")
+	return loc, nil
+}
+
+// FindNearby returns active users who share their location within radiusKM of
+// the given point, closest first. A bounding box on the indexed coordinate
+// columns narrows the candidates before the haversine distance is computed;
+// a box that crosses the antimeridian is split in two longitude ranges.
+func (r *UserRepository) FindNearby(ctx context.Context, excludeUserID int, lat, lng, radiusKM float64, limit, offset int) ([]*models.NearbyUser, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Search)
+	defer cancel()
+
+	latDelta := radiusKM / kmPerDegree
+	lngDelta := 180.0
+	if cos := math.Cos(lat * math.Pi / 180); cos > 0.01 {
+		lngDelta = math.Min(radiusKM/(kmPerDegree*cos), 180.0)
+	}
+	lngRanges := longitudeRanges(lng, lngDelta)
+
+	query := `
+		SELECT id, username, first_name, last_name, avatar_url, city, distance
+		FROM (
+			SELECT id, username, first_name, last_name, avatar_url, city,
+			       2 * 6371 * ASIN(SQRT(
+			           POWER(SIN(RADIANS(latitude - $1) / 2), 2) +
+			           COS(RADIANS($1)) * COS(RADIANS(latitude)) *
+			           POWER(SIN(RADIANS(longitude - $2) / 2), 2)
+			       )) AS distance
+			FROM users u
+			WHERE share_location = TRUE
+			  AND is_active = TRUE
+			  AND id <> $3
+			  AND latitude BETWEEN $4 AND $5
+			  AND (longitude BETWEEN $6 AND $7 OR longitude BETWEEN $11 AND $12)
+			  AND ` + notBlocked("$3", "u.id") + `
+		) candidates
+		WHERE distance <= $8
+		ORDER BY distance, id
+		LIMIT $9 OFFSET $10
+	`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		lat, lng, excludeUserID,
+		lat-latDelta, lat+latDelta,
+		lngRanges[0][0], lngRanges[0][1],
+		radiusKM, limit, offset,
+		lngRanges[1][0], lngRanges[1][1],
+	)
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+		return nil, fmt.Errorf("failed to search nearby users: %w", err)
 	}
-	defer stmt.Close()
+	defer rows.Close()
 
-	for _, interestID := range interestIDs {
-		if _, err := stmt.Exec(userID, interestID); err != nil {
-			return fmt.Errorf("failed to add interest: %w", err)
+	users := []*models.NearbyUser{}
+	for rows.Next() {
+		u := &models.NearbyUser{}
+		var distance float64
+		if err := rows.Scan(&u.ID, &u.Username, &u.FirstName, &u.LastName, &u.AvatarURL, &u.City, &distance); err != nil {
+			return nil, fmt.Errorf("failed to scan nearby user: %w", err)
 		}
+		u.DistanceKM = int(math.Max(1, math.Ceil(distance)))
+		users = append(users, u)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search nearby users: %w", err)
+	}
+
+	return users, nil
+}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+// longitudeRanges returns the longitudes within delta degrees of lng as two
+// ranges in [-180, 180]. When the span does not cross the antimeridian both
+// ranges are the same.
+func longitudeRanges(lng, delta float64) [2][2]float64 {
+	min, max := lng-delta, lng+delta
+	switch {
+	case delta >= 180:
+		return [2][2]float64{{-180, 180}, {-180, 180}}
+	case min < -180:
+		return [2][2]float64{{min + 360, 180}, {-180, max}}
+	case max > 180:
+		return [2][2]float64{{min, 180}, {-180, max - 360}}
 	}
+	return [2][2]float64{{min, max}, {min, max}}
+}
+
+func (r *UserRepository) TouchLastActive(ctx context.Context, userID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
 
+	query := `UPDATE users SET last_active_at = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update last activity: %w", err)
+	}
 	return nil
 }
 
-func (r *UserRepository) CreatePasswordResetToken(token *models.PasswordResetToken) error {
+// GetPublicProfile loads the fields of a user that viewerID may see. The city
+// is only included when the user shares their location, and a block in either
+// direction reports the user as not found.
+func (r *UserRepository) GetPublicProfile(ctx context.Context, viewerID, id int) (*models.PublicProfile, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	profile := &models.PublicProfile{}
 	query := `
-		INSERT INTO password_reset_tokens (user_id, token, expires_at)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at
-	`
-	
-	err := r.db.QueryRow(query, token.UserID, token.Token, token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+		SELECT id, username, first_name, last_name, bio, avatar_url,
+		       CASE WHEN share_location THEN city END, COALESCE(is_private, FALSE), created_at
+		FROM users u
+		WHERE u.id = $1 AND u.is_active = TRUE AND ` + notBlocked("$2", "u.id")
+
+	err := r.db.QueryRowContext(ctx, query, id, viewerID).Scan(
+		&profile.ID,
+		&profile.Username,
+		&profile.FirstName,
+		&profile.LastName,
+		&profile.Bio,
+		&profile.AvatarURL,
+		&profile.City,
+		&profile.IsPrivate,
+		&profile.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create password reset token: %w", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	return profile, nil
+}
+
+func (r *UserRepository) UpdatePrivacy(ctx context.Context, userID int, isPrivate bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `UPDATE users SET is_private = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, isPrivate, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update privacy: %w", err)
+	}
 	return nil
 }
 
-func (r *UserRepository) GetPasswordResetToken(token string) (*models.PasswordResetToken, error) {
-	resetToken := &models.PasswordResetToken{}
-	query := `
-		SELECT id, user_id, token, expires_at, used, created_at
-		FROM password_reset_tokens
-		WHERE token = $1 AND used = FALSE AND expires_at > NOW()
-	`
+// Suspend deactivates a user and revokes every token issued so far.
+func (r *UserRepository) Suspend(ctx context.Context, userID int) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
 
-	err := r.db.QueryRow(query, token).Scan(
-		&resetToken.ID,
-		&resetToken.UserID,
-		&resetToken.Token,
-		&resetToken.ExpiresAt,
-		&resetToken.Used,
-		&resetToken.CreatedAt,
-	)
+	now := time.Now()
+	query := `UPDATE users SET is_active = FALSE, tokens_revoked_at = $1, updated_at = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, now, userID)
+	if err != nil {
+		return fmt.Errorf("failed to suspend user: %w", err)
+	}
+	return nil
+}
+
+// GetTokenState returns what token validation needs to know about a user:
+// whether the account is active and when its tokens were last revoked.
+func (r *UserRepository) GetTokenState(ctx context.Context, userID int) (bool, *time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var isActive bool
+	var revokedAt *time.Time
+	query := `SELECT is_active, tokens_revoked_at FROM users WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&isActive, &revokedAt)
+	if err == sql.ErrNoRows {
+		return false, nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to get user: %w", err)
+	}
 
+	return isActive, revokedAt, nil
+}
+
+func (r *UserRepository) SetAdmin(ctx context.Context, userID int, isAdmin bool) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `UPDATE users SET is_admin = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, isAdmin, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to update admin flag: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) IsAdmin(ctx context.Context, userID int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	var isAdmin bool
+	query := `SELECT COALESCE(is_admin, FALSE) FROM users WHERE id = $1 AND is_active = TRUE`
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&isAdmin)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("invalid or expired token")
+		return false, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get password reset token: %w", err)
+		return false, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	return resetToken, nil
+	return isAdmin, nil
 }
 
-func (r *UserRepository) MarkTokenAsUsed(tokenID int) error {
-	query := `UPDATE password_reset_tokens SET used = TRUE WHERE id = $1`
-	_, err := r.db.Exec(query, tokenID)
+func (r *UserRepository) GetMessagingSettings(ctx context.Context, userID int) (*models.MessagingSettings, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	settings := &models.MessagingSettings{}
+	query := `SELECT COALESCE(messages_from, 'everyone') FROM users WHERE id = $1 AND is_active = TRUE`
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&settings.MessagesFrom)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user %w", ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messaging settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *UserRepository) UpdateMessagingSettings(ctx context.Context, userID int, settings *models.MessagingSettings) error {
+	ctx, cancel := context.WithTimeout(ctx, timeouts.Default)
+	defer cancel()
+
+	query := `UPDATE users SET messages_from = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.ExecContext(ctx, query, settings.MessagesFrom, time.Now(), userID)
 	if err != nil {
-		return fmt.Errorf("failed to mark token as used: %w", err)
+		return fmt.Errorf("failed to update messaging settings: %w", err)
 	}
 	return nil
 }
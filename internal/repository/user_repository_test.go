@@ -0,0 +1,22 @@
+package repository
+
+import "testing"
+
+func TestLongitudeRanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		lng, delta float64
+		want       [2][2]float64
+	}{
+		{"inside", 10, 2, [2][2]float64{{8, 12}, {8, 12}}},
+		{"crosses east", 179, 2, [2][2]float64{{177, 180}, {-180, -179}}},
+		{"crosses west", -179, 2, [2][2]float64{{179, 180}, {-180, -177}}},
+		{"whole circle", 0, 180, [2][2]float64{{-180, 180}, {-180, 180}}},
+	}
+
+	for _, tt := range tests {
+		if got := longitudeRanges(tt.lng, tt.delta); got != tt.want {
+			t.Errorf("%s: longitudeRanges(%v, %v) = %v, want %v", tt.name, tt.lng, tt.delta, got, tt.want)
+		}
+	}
+}
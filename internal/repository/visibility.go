@@ -0,0 +1,18 @@
+package repository
+
+import "fmt"
+
+// notBlocked returns a SQL predicate that is true when neither of the two
+// user ID expressions has blocked the other. Every query that returns other
+// members to a viewer must include it, for example
+//
+//	WHERE u.is_active = TRUE AND ` + notBlocked("$1", "u.id")
+//
+// so that blocked users never see each other anywhere in the API.
+func notBlocked(userA, userB string) string {
+	return fmt.Sprintf(`NOT EXISTS (
+		SELECT 1 FROM user_blocks blk
+		WHERE (blk.blocker_id = %[1]s AND blk.blocked_id = %[2]s)
+		   OR (blk.blocker_id = %[2]s AND blk.blocked_id = %[1]s)
+	)`, userA, userB)
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -13,52 +16,79 @@ import (
 	"windsurf-project/internal/repository"
 )
 
+var (
+	errEmailTaken    = &Error{Kind: ErrConflict, Code: "email_taken", Field: "email", Message: "user with this email already exists"}
+	errUsernameTaken = &Error{Kind: ErrConflict, Code: "username_taken", Field: "username", Message: "username is already taken"}
+)
+
 type AuthService struct {
-	userRepo  *repository.UserRepository
+	users     repository.UserStore
+	tokens    repository.TokenStore
+	interests repository.InterestStore
+	uow       repository.UnitOfWork
 	jwtSecret string
 }
 
-func NewAuthService(userRepo *repository.UserRepository, jwtSecret string) *AuthService {
+func NewAuthService(users repository.UserStore, tokens repository.TokenStore, interests repository.InterestStore, uow repository.UnitOfWork, jwtSecret string) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
+		users:     users,
+		tokens:    tokens,
+		interests: interests,
+		uow:       uow,
 		jwtSecret: jwtSecret,
 	}
 }
 
-func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
-	// Check if user already exists
-	existingUser, _ := s.userRepo.GetByEmail(req.Email)
-	if existingUser != nil {
-		return nil, fmt.Errorf("user with this email already exists")
-	}
-
-	// Hash password
+// Register creates the account and joins its interest groups in one unit
+// of work, so a failure never leaves a user without the interests they
+// asked for.
+func (s *AuthService) Register(ctx context.Context, req *models.RegisterRequest) (*models.AuthResponse, error) {
+	// Hash password outside the transaction, it is the slow part
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Create user
-	user := &models.User{
-		Email:        req.Email,
-		Username:     req.Username,
-		PasswordHash: string(hashedPassword),
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		IsVerified:   false,
-		IsActive:     true,
-	}
+	var user *models.User
+	err = s.uow.Do(ctx, func(tx repository.Stores) error {
+		// Check if user already exists
+		existingUser, _ := tx.Users.GetByEmail(ctx, req.Email)
+		if existingUser != nil {
+			return errEmailTaken
+		}
 
-	if err := s.userRepo.Create(user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
-	}
+		// Create user
+		user = &models.User{
+			Email:        req.Email,
+			Username:     req.Username,
+			PasswordHash: string(hashedPassword),
+			FirstName:    req.FirstName,
+			LastName:     req.LastName,
+			IsVerified:   false,
+			IsActive:     true,
+		}
+		if err := tx.Users.Create(ctx, user); err != nil {
+			switch {
+			case errors.Is(err, repository.ErrEmailTaken):
+				return errEmailTaken
+			case errors.Is(err, repository.ErrUsernameTaken):
+				return errUsernameTaken
+			}
+			return fmt.Errorf("failed to create user: %w", err)
+		}
 
-	// Add user interests if provided
-	if len(req.Interests) > 0 {
-		if err := s.userRepo.AddUserInterests(user.ID, req.Interests); err != nil {
-			// Log error but don't fail registration
-			fmt.Printf("Warning: failed to add user interests: %v\n", err)
+		// Add user interests if provided
+		if err := tx.Interests.AddUserInterests(ctx, user.ID, req.Interests); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return InvalidField("interests", "interests contains an unknown interest group")
+			}
+			return fmt.Errorf("failed to add interests: %w", err)
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate JWT token
@@ -73,21 +103,25 @@ func (s *AuthService) Register(req *models.RegisterRequest) (*models.AuthRespons
 	}, nil
 }
 
-func (s *AuthService) Login(req *models.LoginRequest) (*models.AuthResponse, error) {
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest) (*models.AuthResponse, error) {
 	// Get user by email
-	user, err := s.userRepo.GetByEmail(req.Email)
+	user, err := s.users.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("invalid email or password")
+		return nil, Unauthorized("invalid email or password")
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		return nil, fmt.Errorf("account is deactivated")
+		return nil, Forbidden("account is deactivated")
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, fmt.Errorf("invalid email or password")
+		return nil, Unauthorized("invalid email or password")
+	}
+
+	if err := s.users.TouchLastActive(ctx, user.ID); err != nil {
+		log.Printf("auth: user %d: failed to record login activity: %v", user.ID, err)
 	}
 
 	// Generate JWT token
@@ -102,9 +136,9 @@ func (s *AuthService) Login(req *models.LoginRequest) (*models.AuthResponse, err
 	}, nil
 }
 
-func (s *AuthService) RequestPasswordReset(email string) (string, error) {
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) (string, error) {
 	// Get user by email
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.users.GetByEmail(ctx, email)
 	if err != nil {
 		// Don't reveal if user exists or not for security
 		return "", nil
@@ -125,18 +159,19 @@ func (s *AuthService) RequestPasswordReset(email string) (string, error) {
 		Used:      false,
 	}
 
-	if err := s.userRepo.CreatePasswordResetToken(resetToken); err != nil {
+	if err := s.tokens.CreatePasswordResetToken(ctx, resetToken); err != nil {
 		return "", fmt.Errorf("failed to create reset token: %w", err)
 	}
 
 	return token, nil
 }
 
-func (s *AuthService) ResetPassword(req *models.PasswordResetConfirm) error {
-	// Get and validate token
-	resetToken, err := s.userRepo.GetPasswordResetToken(req.Token)
-	if err != nil {
-		return fmt.Errorf("invalid or expired token")
+// ResetPassword sets the new password and consumes the token in one unit of
+// work, so a token can never be used twice, even by concurrent requests.
+func (s *AuthService) ResetPassword(ctx context.Context, req *models.PasswordResetConfirm) error {
+	// Reject unknown tokens before paying for the hash
+	if _, err := s.tokens.GetPasswordResetToken(ctx, req.Token); err != nil {
+		return InvalidField("token", "invalid or expired token")
 	}
 
 	// Hash new password
@@ -145,17 +180,25 @@ func (s *AuthService) ResetPassword(req *models.PasswordResetConfirm) error {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// Update user password
-	if err := s.userRepo.UpdatePassword(resetToken.UserID, string(hashedPassword)); err != nil {
-		return fmt.Errorf("failed to update password: %w", err)
-	}
+	return s.uow.Do(ctx, func(tx repository.Stores) error {
+		// Read the token again, another request may have used it meanwhile
+		resetToken, err := tx.Tokens.GetPasswordResetToken(ctx, req.Token)
+		if err != nil {
+			return InvalidField("token", "invalid or expired token")
+		}
 
-	// Mark token as used
-	if err := s.userRepo.MarkTokenAsUsed(resetToken.ID); err != nil {
-		return fmt.Errorf("failed to mark token as used: %w", err)
-	}
+		// Update user password
+		if err := tx.Users.UpdatePassword(ctx, resetToken.UserID, string(hashedPassword)); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
 
-	return nil
+		// Mark token as used
+		if err := tx.Tokens.MarkTokenAsUsed(ctx, resetToken.ID); err != nil {
+			return fmt.Errorf("failed to mark token as used: %w", err)
+		}
+
+		return nil
+	})
 }
 
 func (s *AuthService) generateToken(user *models.User) (string, error) {
@@ -176,7 +219,7 @@ func (s *AuthService) generateToken(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
-func (s *AuthService) ValidateToken(tokenString string) (*jwt.MapClaims, error) {
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -185,12 +228,50 @@ func (s *AuthService) ValidateToken(tokenString string) (*jwt.MapClaims, error)
 	})
 
 	if err != nil {
+		return nil, Unauthorized("%s", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, Unauthorized("invalid token")
+	}
+
+	if err := s.checkNotRevoked(ctx, claims); err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return &claims, nil
+	return &claims, nil
+}
+
+// checkNotRevoked rejects tokens of deactivated users and tokens issued
+// before the user's tokens were last revoked, e.g. by a suspension.
+func (s *AuthService) checkNotRevoked(ctx context.Context, claims jwt.MapClaims) error {
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return Unauthorized("invalid token")
+	}
+
+	isActive, revokedAt, err := s.users.GetTokenState(ctx, int(userID))
+	if errors.Is(err, ErrNotFound) {
+		return Unauthorized("invalid token")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check token: %w", err)
+	}
+	if !isActive {
+		return Forbidden("account is deactivated")
+	}
+
+	if revokedAt != nil {
+		issuedAt, err := claims.GetIssuedAt()
+		if err != nil || issuedAt == nil || !issuedAt.After(*revokedAt) {
+			return Unauthorized("token has been revoked")
+		}
 	}
 
-	return nil, fmt.Errorf("invalid token")
+	return nil
+}
+
+func (s *AuthService) IsAdmin(ctx context.Context, userID int) (bool, error) {
+	return s.users.IsAdmin(ctx, userID)
 }
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository/memory"
+)
+
+func newTestAuthService() (*AuthService, *memory.Store) {
+	store := memory.New()
+	return NewAuthService(store, store, store, store, "test-secret"), store
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	ctx := context.Background()
+	auth, store := newTestAuthService()
+
+	groupID, err := store.Upsert(ctx, "Hiking", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered, err := auth.Register(ctx, &models.RegisterRequest{
+		Email:     "ada@example.com",
+		Username:  "ada",
+		Password:  "correct horse",
+		Interests: []int{groupID},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if registered.Token == "" || registered.User.ID == 0 {
+		t.Fatalf("Register = %+v, want a token and a user", registered)
+	}
+
+	interests, err := store.ListUserInterests(ctx, registered.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interests) != 1 || interests[0] != groupID {
+		t.Errorf("interests = %v, want [%d]", interests, groupID)
+	}
+
+	if _, err := auth.Register(ctx, &models.RegisterRequest{
+		Email:    "ada@example.com",
+		Username: "ada2",
+		Password: "correct horse",
+	}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Register with a taken email = %v, want ErrConflict", err)
+	}
+
+	_, err = auth.Register(ctx, &models.RegisterRequest{
+		Email:    "other@example.com",
+		Username: "ada",
+		Password: "correct horse",
+	})
+	if !errors.Is(err, ErrConflict) || Code(err) != "username_taken" {
+		t.Errorf("Register with a taken username = %v, want ErrConflict", err)
+	}
+
+	if _, err := auth.Login(ctx, &models.LoginRequest{Email: "ada@example.com", Password: "wrong"}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Login with a wrong password = %v, want ErrUnauthorized", err)
+	}
+
+	loggedIn, err := auth.Login(ctx, &models.LoginRequest{Email: "ada@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if _, err := auth.ValidateToken(ctx, loggedIn.Token); err != nil {
+		t.Errorf("ValidateToken: %v", err)
+	}
+}
+
+func TestResetPasswordUsesTokenOnce(t *testing.T) {
+	ctx := context.Background()
+	auth, _ := newTestAuthService()
+
+	if _, err := auth.Register(ctx, &models.RegisterRequest{
+		Email:    "grace@example.com",
+		Username: "grace",
+		Password: "old password",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := auth.RequestPasswordReset(ctx, "grace@example.com")
+	if err != nil || token == "" {
+		t.Fatalf("RequestPasswordReset = %q, %v", token, err)
+	}
+
+	reset := &models.PasswordResetConfirm{Token: token, NewPassword: "new password"}
+	if err := auth.ResetPassword(ctx, reset); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if err := auth.ResetPassword(ctx, reset); err == nil {
+		t.Error("ResetPassword accepted a used token")
+	}
+
+	if _, err := auth.Login(ctx, &models.LoginRequest{Email: "grace@example.com", Password: "new password"}); err != nil {
+		t.Errorf("Login with the new password: %v", err)
+	}
+}
+
+func TestSuspensionRevokesTokens(t *testing.T) {
+	ctx := context.Background()
+	auth, store := newTestAuthService()
+
+	registered, err := auth.Register(ctx, &models.RegisterRequest{
+		Email:    "linus@example.com",
+		Username: "linus",
+		Password: "password1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Suspend(ctx, registered.User.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.ValidateToken(ctx, registered.Token); err == nil {
+		t.Error("ValidateToken accepted a token of a suspended user")
+	}
+}
+
+func TestRegisterIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	auth, store := newTestAuthService()
+
+	_, err := auth.Register(ctx, &models.RegisterRequest{
+		Email:     "ken@example.com",
+		Username:  "ken",
+		Password:  "password1",
+		Interests: []int{42},
+	})
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Register with an unknown interest group = %v, want ErrValidation", err)
+	}
+
+	if _, err := store.GetByEmail(ctx, "ken@example.com"); err == nil {
+		t.Error("failed registration left the user behind")
+	}
+}
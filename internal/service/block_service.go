@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+)
+
+type BlockService struct {
+	userRepo  *repository.UserRepository
+	blockRepo *repository.BlockRepository
+}
+
+func NewBlockService(userRepo *repository.UserRepository, blockRepo *repository.BlockRepository) *BlockService {
+	return &BlockService{
+		userRepo:  userRepo,
+		blockRepo: blockRepo,
+	}
+}
+
+func (s *BlockService) Block(ctx context.Context, userID, targetID int) error {
+	if userID == targetID {
+		return Validation("you cannot block yourself")
+	}
+	if _, err := s.userRepo.GetByID(ctx, targetID); err != nil {
+		return err
+	}
+	return s.blockRepo.Block(ctx, userID, targetID)
+}
+
+func (s *BlockService) Unblock(ctx context.Context, userID, targetID int) error {
+	return s.blockRepo.Unblock(ctx, userID, targetID)
+}
+
+func (s *BlockService) ListBlocked(ctx context.Context, userID int) ([]*models.BlockedUser, error) {
+	return s.blockRepo.ListBlocked(ctx, userID)
+}
+
+func (s *BlockService) Mute(ctx context.Context, userID, targetID int) error {
+	if userID == targetID {
+		return Validation("you cannot mute yourself")
+	}
+	if _, err := s.userRepo.GetByID(ctx, targetID); err != nil {
+		return err
+	}
+	return s.blockRepo.Mute(ctx, userID, targetID)
+}
+
+func (s *BlockService) Unmute(ctx context.Context, userID, targetID int) error {
+	return s.blockRepo.Unmute(ctx, userID, targetID)
+}
+
+func (s *BlockService) ListMuted(ctx context.Context, userID int) ([]*models.BlockedUser, error) {
+	return s.blockRepo.ListMuted(ctx, userID)
+}
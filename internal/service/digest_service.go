@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"windsurf-project/internal/lifecycle"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+)
+
+const (
+	digestBatchSize       = 200
+	digestMembersPerGroup = 5
+)
+
+type DigestService struct {
+	digestRepo   *repository.DigestRepository
+	emailService *EmailService
+	secret       []byte
+	apiURL       string
+}
+
+func NewDigestService(digestRepo *repository.DigestRepository, emailService *EmailService, secret, apiURL string) *DigestService {
+	return &DigestService{
+		digestRepo:   digestRepo,
+		emailService: emailService,
+		secret:       []byte(secret),
+		apiURL:       apiURL,
+	}
+}
+
+func (s *DigestService) GetSettings(ctx context.Context, userID int) (*models.DigestSettings, error) {
+	return s.digestRepo.GetSettings(ctx, userID)
+}
+
+func (s *DigestService) UpdateSettings(ctx context.Context, userID int, settings *models.DigestSettings) error {
+	switch settings.Frequency {
+	case models.DigestDaily, models.DigestWeekly, models.DigestOff:
+	default:
+		return InvalidField("frequency", "frequency must be %q, %q or %q", models.DigestDaily, models.DigestWeekly, models.DigestOff)
+	}
+	return s.digestRepo.UpdateSettings(ctx, userID, settings)
+}
+
+// CheckUnsubscribeToken reports whether token is a valid unsubscribe token,
+// without changing anything.
+func (s *DigestService) CheckUnsubscribeToken(token string) error {
+	_, err := s.parseUnsubscribeToken(token)
+	return err
+}
+
+// Unsubscribe turns off the digest of the user an unsubscribe token was
+// issued to.
+func (s *DigestService) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := s.parseUnsubscribeToken(token)
+	if err != nil {
+		return err
+	}
+	return s.digestRepo.UpdateSettings(ctx, userID, &models.DigestSettings{Frequency: models.DigestOff})
+}
+
+// SendDue sends every digest that is due. Recipients are claimed before
+// their email is sent, so replicas running the job at the same time never
+// send the same digest twice. Users with nothing new get no email, but
+// their period still advances.
+func (s *DigestService) SendDue(ctx context.Context) error {
+	// Postgres keeps microseconds; the claim is released by matching it.
+	now := time.Now().Truncate(time.Microsecond)
+	afterID := 0
+	for {
+		recipients, err := s.digestRepo.ClaimDue(ctx, now, afterID, digestBatchSize)
+		if err != nil {
+			return err
+		}
+		for i, rc := range recipients {
+			if ctx.Err() != nil {
+				// Hand the unsent rest back for the next run, even though
+				// the job itself is being stopped.
+				s.release(context.WithoutCancel(ctx), recipients[i:], now)
+				return ctx.Err()
+			}
+			if err := s.send(ctx, rc); err != nil {
+				log.Printf("digest: user %d: %v", rc.ID, err)
+				s.release(ctx, recipients[i:i+1], now)
+			}
+		}
+		if len(recipients) < digestBatchSize {
+			return nil
+		}
+		afterID = recipients[len(recipients)-1].ID
+	}
+}
+
+// Run sends due digests immediately and then on every interval until stop is
+// closed.
+func (s *DigestService) Run(interval time.Duration, stop <-chan struct{}) {
+	ctx, cancel := lifecycle.StopContext(stop)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SendDue(ctx); err != nil {
+			log.Printf("digest: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *DigestService) release(ctx context.Context, recipients []*models.DigestRecipient, claimedAt time.Time) {
+	for _, rc := range recipients {
+		if err := s.digestRepo.ReleaseClaim(ctx, rc, claimedAt); err != nil {
+			log.Printf("digest: user %d: %v", rc.ID, err)
+		}
+	}
+}
+
+func (s *DigestService) send(ctx context.Context, rc *models.DigestRecipient) error {
+	groups, err := s.digestRepo.NewMembers(ctx, rc.ID, rc.Since, digestMembersPerGroup)
+	if err != nil {
+		return err
+	}
+
+	if len(groups) > 0 {
+		digest := &models.Digest{
+			Username:  rc.Username,
+			Frequency: rc.Frequency,
+			Since:     rc.Since,
+			Groups:    groups,
+		}
+		unsubscribeURL := fmt.Sprintf("%s/api/digest/unsubscribe?token=%s", s.apiURL, url.QueryEscape(s.unsubscribeToken(rc.ID)))
+		return s.emailService.SendDigestEmail(rc.Email, digest, unsubscribeURL)
+	}
+	return nil
+}
+
+// unsubscribeToken signs the user ID so the unsubscribe link works without
+// logging in and cannot be forged for other users.
+func (s *DigestService) unsubscribeToken(userID int) string {
+	id := strconv.Itoa(userID)
+	return id + "." + s.sign(id)
+}
+
+func (s *DigestService) parseUnsubscribeToken(token string) (int, error) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(id))) {
+		return 0, InvalidField("token", "invalid unsubscribe token")
+	}
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, InvalidField("token", "invalid unsubscribe token")
+	}
+	return userID, nil
+}
+
+func (s *DigestService) sign(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("digest-unsubscribe:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
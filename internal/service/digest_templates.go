@@ -0,0 +1,104 @@
+package service
+
+import (
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"windsurf-project/internal/models"
+)
+
+// digestView is rendered by both digest templates.
+type digestView struct {
+	Username       string
+	Period         string
+	Frequency      string
+	Groups         []digestGroupView
+	FrontendURL    string
+	UnsubscribeURL string
+}
+
+type digestGroupView struct {
+	Name           string
+	NewMemberCount int
+	NewMembers     []digestMemberView
+	More           int
+}
+
+type digestMemberView struct {
+	ID          int
+	DisplayName string
+}
+
+func newDigestView(digest *models.Digest, frontendURL, unsubscribeURL string) *digestView {
+	view := &digestView{
+		Username:       digest.Username,
+		Period:         "this week",
+		Frequency:      "weekly",
+		FrontendURL:    frontendURL,
+		UnsubscribeURL: unsubscribeURL,
+	}
+	if digest.Frequency == models.DigestDaily {
+		view.Period = "today"
+		view.Frequency = "daily"
+	}
+
+	for _, g := range digest.Groups {
+		gv := digestGroupView{
+			Name:           g.Name,
+			NewMemberCount: g.NewMemberCount,
+			More:           g.NewMemberCount - len(g.NewMembers),
+		}
+		for _, m := range g.NewMembers {
+			name := m.Username
+			if m.FirstName != nil && *m.FirstName != "" {
+				name = *m.FirstName + " (@" + m.Username + ")"
+			}
+			gv.NewMembers = append(gv.NewMembers, digestMemberView{ID: m.ID, DisplayName: name})
+		}
+		view.Groups = append(view.Groups, gv)
+	}
+	return view
+}
+
+var digestTextTemplate = texttemplate.Must(texttemplate.New("digest").Parse(`Hello {{.Username}},
+
+Here is what happened in your interest groups {{.Period}}.
+{{range .Groups}}
+{{.Name}}: {{.NewMemberCount}} new member{{if ne .NewMemberCount 1}}s{{end}}
+{{- range .NewMembers}}
+  - {{.DisplayName}}: {{$.FrontendURL}}/users/{{.ID}}
+{{- end}}
+{{- if gt .More 0}}
+  ...and {{.More}} more
+{{- end}}
+{{end}}
+Say hello and find more members: {{.FrontendURL}}
+
+You receive this digest {{.Frequency}}. Unsubscribe with one click: {{.UnsubscribeURL}}
+
+Best regards,
+Social App Team
+`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #222;">
+<p>Hello {{.Username}},</p>
+<p>Here is what happened in your interest groups {{.Period}}.</p>
+{{range .Groups}}
+<h3>{{.Name}}: {{.NewMemberCount}} new member{{if ne .NewMemberCount 1}}s{{end}}</h3>
+<ul>
+{{- range .NewMembers}}
+  <li><a href="{{$.FrontendURL}}/users/{{.ID}}">{{.DisplayName}}</a></li>
+{{- end}}
+{{- if gt .More 0}}
+  <li>and {{.More}} more</li>
+{{- end}}
+</ul>
+{{end}}
+<p><a href="{{.FrontendURL}}">Say hello and find more members</a></p>
+<p>Best regards,<br>Social App Team</p>
+<p style="font-size: 12px; color: #888;">You receive this digest {{.Frequency}}. <a href="{{.UnsubscribeURL}}">Unsubscribe</a></p>
+</body>
+</html>
+`))
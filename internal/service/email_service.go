@@ -1,18 +1,36 @@
 package service
 
 import (
+	"bytes"
 	"fmt"
+	"log"
+	"mime/multipart"
+	"mime/quotedprintable"
 	"net/smtp"
+	"net/textproto"
 
 	"windsurf-project/internal/config"
+	"windsurf-project/internal/lifecycle"
+	"windsurf-project/internal/models"
 )
 
 type EmailService struct {
-	cfg *config.Config
+	cfg        *config.Config
+	background *lifecycle.Registry
 }
 
-func NewEmailService(cfg *config.Config) *EmailService {
-	return &EmailService{cfg: cfg}
+func NewEmailService(cfg *config.Config, background *lifecycle.Registry) *EmailService {
+	return &EmailService{cfg: cfg, background: background}
+}
+
+// SendAsync sends an email without blocking the caller. Shutdown waits for
+// pending sends, and failures are logged.
+func (s *EmailService) SendAsync(send func() error) {
+	s.background.Go(func() {
+		if err := send(); err != nil {
+			log.Printf("email: %v", err)
+		}
+	})
 }
 
 func (s *EmailService) SendPasswordResetEmail(email, token string) error {
@@ -44,20 +62,7 @@ Best regards,
 Social App Team
 `, resetURL)
 
-	message := fmt.Sprintf("From: %s\r\n", s.cfg.SMTPUser)
-	message += fmt.Sprintf("To: %s\r\n", email)
-	message += fmt.Sprintf("Subject: %s\r\n", subject)
-	message += "\r\n" + body
-
-	auth := smtp.PlainAuth("", s.cfg.SMTPUser, s.cfg.SMTPPassword, s.cfg.SMTPHost)
-	addr := fmt.Sprintf("%s:%s", s.cfg.SMTPHost, s.cfg.SMTPPort)
-
-	err := smtp.SendMail(addr, auth, s.cfg.SMTPUser, []string{email}, []byte(message))
-	if err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
-	}
-
-	return nil
+	return s.send(email, subject, body)
 }
 
 func (s *EmailService) SendWelcomeEmail(email, username string) error {
@@ -88,18 +93,157 @@ Best regards,
 Social App Team
 `, username)
 
+	return s.send(email, subject, body)
+}
+
+func (s *EmailService) SendModerationWarning(email, username string, note *string) error {
+	reason := "a report about your account"
+	if note != nil && *note != "" {
+		reason = *note
+	}
+
+	if s.cfg.SMTPUser == "" || s.cfg.SMTPPassword == "" {
+		// In development, just log
+		fmt.Printf("\n=== MODERATION WARNING ===\n")
+		fmt.Printf("Email: %s\n", email)
+		fmt.Printf("Username: %s\n", username)
+		fmt.Printf("Reason: %s\n", reason)
+		fmt.Printf("==========================\n\n")
+		return nil
+	}
+
+	subject := "A warning about your Social App account"
+	body := fmt.Sprintf(`
+Hello %s,
+
+Our moderators have reviewed %s and issued a warning on your account.
+
+Please review our community guidelines. Further violations may lead to your account being suspended.
+
+Best regards,
+Social App Team
+`, username, reason)
+
+	return s.send(email, subject, body)
+}
+
+func (s *EmailService) send(to, subject, body string) error {
 	message := fmt.Sprintf("From: %s\r\n", s.cfg.SMTPUser)
-	message += fmt.Sprintf("To: %s\r\n", email)
+	message += fmt.Sprintf("To: %s\r\n", to)
 	message += fmt.Sprintf("Subject: %s\r\n", subject)
 	message += "\r\n" + body
 
+	return s.deliver(to, []byte(message))
+}
+
+// sendAlternative sends a multipart/alternative message with plain-text and
+// HTML bodies. headers are added to the message as-is.
+func (s *EmailService) sendAlternative(to, subject, text, html string, headers map[string]string) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	message := fmt.Sprintf("From: %s\r\n", s.cfg.SMTPUser)
+	message += fmt.Sprintf("To: %s\r\n", to)
+	message += fmt.Sprintf("Subject: %s\r\n", subject)
+	for name, value := range headers {
+		message += fmt.Sprintf("%s: %s\r\n", name, value)
+	}
+	message += "MIME-Version: 1.0\r\n"
+	message += fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q\r\n", mw.Boundary())
+	message += "\r\n" + body.String()
+
+	return s.deliver(to, []byte(message))
+}
+
+func (s *EmailService) deliver(to string, message []byte) error {
 	auth := smtp.PlainAuth("", s.cfg.SMTPUser, s.cfg.SMTPPassword, s.cfg.SMTPHost)
 	addr := fmt.Sprintf("%s:%s", s.cfg.SMTPHost, s.cfg.SMTPPort)
 
-	err := smtp.SendMail(addr, auth, s.cfg.SMTPUser, []string{email}, []byte(message))
+	err := smtp.SendMail(addr, auth, s.cfg.SMTPUser, []string{to}, message)
 	if err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
 	return nil
 }
+
+func (s *EmailService) SendNotificationEmail(email, username, summary string) error {
+	if s.cfg.SMTPUser == "" || s.cfg.SMTPPassword == "" {
+		// In development, just log
+		fmt.Printf("\n=== NOTIFICATION EMAIL ===\n")
+		fmt.Printf("Email: %s\n", email)
+		fmt.Printf("Summary: %s\n", summary)
+		fmt.Printf("==========================\n\n")
+		return nil
+	}
+
+	subject := summary
+	body := fmt.Sprintf(`
+Hello %s,
+
+%s.
+
+See all your notifications: %s/notifications
+
+You can choose which notifications you receive by email in your settings.
+
+Best regards,
+Social App Team
+`, username, summary, s.cfg.FrontendURL)
+
+	return s.send(email, subject, body)
+}
+
+// SendDigestEmail sends a user's activity digest with HTML and plain-text
+// bodies. unsubscribeURL turns the digest off in one click, from the link in
+// the message or from the mail client via List-Unsubscribe.
+func (s *EmailService) SendDigestEmail(email string, digest *models.Digest, unsubscribeURL string) error {
+	view := newDigestView(digest, s.cfg.FrontendURL, unsubscribeURL)
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, view); err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+	if err := digestHTMLTemplate.Execute(&html, view); err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	if s.cfg.SMTPUser == "" || s.cfg.SMTPPassword == "" {
+		// In development, just log the plain-text version
+		fmt.Printf("\n=== DIGEST EMAIL ===\n")
+		fmt.Printf("Email: %s\n", email)
+		fmt.Print(text.String())
+		fmt.Printf("====================\n\n")
+		return nil
+	}
+
+	subject := fmt.Sprintf("Your %s Social App digest", view.Frequency)
+	headers := map[string]string{
+		"List-Unsubscribe":      fmt.Sprintf("<%s>", unsubscribeURL),
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+
+	return s.sendAlternative(email, subject, text.String(), html.String(), headers)
+}
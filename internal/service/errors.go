@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"windsurf-project/internal/repository"
+)
+
+// Error kinds. Every failure the client caused wraps exactly one of them, so
+// handlers can pick a status code with errors.Is. Anything that wraps none
+// of them is an internal failure whose message must not reach the client.
+var (
+	ErrNotFound     = repository.ErrNotFound
+	ErrConflict     = repository.ErrConflict
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// Error is a failure of one of the kinds above with a message meant for the
+// client. Code optionally names the failure more precisely than its kind,
+// e.g. "email_taken", and Field names the request field a validation error
+// is about.
+type Error struct {
+	Kind    error
+	Code    string
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Kind }
+
+func newError(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func NotFound(format string, args ...interface{}) error {
+	return newError(ErrNotFound, format, args...)
+}
+
+func Conflict(format string, args ...interface{}) error {
+	return newError(ErrConflict, format, args...)
+}
+
+func Validation(format string, args ...interface{}) error {
+	return newError(ErrValidation, format, args...)
+}
+
+// InvalidField is a validation error about one request field.
+func InvalidField(field, format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+func Unauthorized(format string, args ...interface{}) error {
+	return newError(ErrUnauthorized, format, args...)
+}
+
+func Forbidden(format string, args ...interface{}) error {
+	return newError(ErrForbidden, format, args...)
+}
+
+func RateLimited(format string, args ...interface{}) error {
+	return newError(ErrRateLimited, format, args...)
+}
+
+// Code returns the code of the Error in err's chain, if it has one.
+func Code(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// Field returns the request field the Error in err's chain is about, if any.
+func Field(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Field
+	}
+	return ""
+}
+
+// Message returns the part of err that is safe to show to the client: the
+// message of the Error or repository error that carries its kind. It is
+// empty for internal failures.
+func Message(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Message
+	}
+	for _, kind := range []error{ErrNotFound, ErrConflict} {
+		if !errors.Is(err, kind) {
+			continue
+		}
+		// Repositories wrap the kind directly, e.g. "user not found", but
+		// services may add their own context around that.
+		for e := err; e != nil; e = errors.Unwrap(e) {
+			if errors.Unwrap(e) == kind {
+				return e.Error()
+			}
+		}
+		return kind.Error()
+	}
+	return ""
+}
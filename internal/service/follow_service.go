@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+	"windsurf-project/pkg/cursor"
+)
+
+type FollowService struct {
+	userRepo            *repository.UserRepository
+	followRepo          *repository.FollowRepository
+	uow                 repository.UnitOfWork
+	notificationService *NotificationService
+}
+
+func NewFollowService(userRepo *repository.UserRepository, followRepo *repository.FollowRepository, uow repository.UnitOfWork, notificationService *NotificationService) *FollowService {
+	return &FollowService{
+		userRepo:            userRepo,
+		followRepo:          followRepo,
+		uow:                 uow,
+		notificationService: notificationService,
+	}
+}
+
+// GetProfile returns a user's public profile with connection counts and the
+// viewer's relationship to them.
+func (s *FollowService) GetProfile(ctx context.Context, viewerID, userID int) (*models.PublicProfile, error) {
+	profile, err := s.userRepo.GetPublicProfile(ctx, viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, following, friends, err := s.followRepo.Counts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	profile.FollowerCount = followers
+	profile.FollowingCount = following
+	profile.FriendCount = friends
+
+	if profile.ViewerRelationship, err = s.relationship(ctx, viewerID, userID); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *FollowService) relationship(ctx context.Context, viewerID, userID int) (string, error) {
+	if viewerID == userID {
+		return models.RelationshipSelf, nil
+	}
+
+	status, followedBack, err := s.followRepo.Mutual(ctx, viewerID, userID)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case status == models.FollowPending:
+		return models.RelationshipPending, nil
+	case status == models.FollowAccepted && followedBack:
+		return models.RelationshipFriends, nil
+	case status == models.FollowAccepted:
+		return models.RelationshipFollowing, nil
+	}
+	return models.RelationshipNone, nil
+}
+
+// Follow follows another user. Following a private profile creates a pending
+// request that the owner has to accept.
+func (s *FollowService) Follow(ctx context.Context, followerID, followeeID int) (*models.FollowStatus, error) {
+	if followerID == followeeID {
+		return nil, Validation("you cannot follow yourself")
+	}
+
+	target, err := s.userRepo.GetPublicProfile(ctx, followerID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := models.FollowAccepted
+	if target.IsPrivate {
+		status = models.FollowPending
+	}
+
+	result, created, err := s.followRepo.Follow(ctx, followerID, followeeID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		notificationType := models.NotificationNewFollower
+		if result == models.FollowPending {
+			notificationType = models.NotificationFollowRequest
+		}
+		s.notificationService.Notify(ctx, followeeID, notificationType, followerID, "", 0)
+	}
+
+	return &models.FollowStatus{Status: result}, nil
+}
+
+func (s *FollowService) Unfollow(ctx context.Context, followerID, followeeID int) error {
+	return s.followRepo.Unfollow(ctx, followerID, followeeID)
+}
+
+func (s *FollowService) AcceptRequest(ctx context.Context, userID, followerID int) error {
+	ok, err := s.followRepo.AcceptRequest(ctx, userID, followerID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NotFound("follow request not found")
+	}
+	return nil
+}
+
+func (s *FollowService) RejectRequest(ctx context.Context, userID, followerID int) error {
+	ok, err := s.followRepo.RejectRequest(ctx, userID, followerID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NotFound("follow request not found")
+	}
+	return nil
+}
+
+// UpdatePrivacy switches a profile between public and private. Going public
+// accepts every outstanding follow request in the same unit of work, so a
+// public profile is never left with pending requests.
+func (s *FollowService) UpdatePrivacy(ctx context.Context, userID int, isPrivate bool) error {
+	return s.uow.Do(ctx, func(stores repository.Stores) error {
+		if err := stores.Users.UpdatePrivacy(ctx, userID, isPrivate); err != nil {
+			return err
+		}
+		if !isPrivate {
+			return stores.Follows.AcceptAllPending(ctx, userID)
+		}
+		return nil
+	})
+}
+
+func (s *FollowService) ListFollowers(ctx context.Context, viewerID, userID int, after string, limit int) (*models.ConnectionPage, error) {
+	if err := s.checkCanViewConnections(ctx, viewerID, userID); err != nil {
+		return nil, err
+	}
+	return s.page(after, limit, func(beforeAt time.Time, beforeID int) ([]*models.Connection, error) {
+		return s.followRepo.ListFollowers(ctx, viewerID, userID, models.FollowAccepted, beforeAt, beforeID, limit+1)
+	})
+}
+
+func (s *FollowService) ListFollowing(ctx context.Context, viewerID, userID int, after string, limit int) (*models.ConnectionPage, error) {
+	if err := s.checkCanViewConnections(ctx, viewerID, userID); err != nil {
+		return nil, err
+	}
+	return s.page(after, limit, func(beforeAt time.Time, beforeID int) ([]*models.Connection, error) {
+		return s.followRepo.ListFollowing(ctx, viewerID, userID, beforeAt, beforeID, limit+1)
+	})
+}
+
+// ListRequests returns the pending follow requests addressed to userID.
+func (s *FollowService) ListRequests(ctx context.Context, userID int, after string, limit int) (*models.ConnectionPage, error) {
+	return s.page(after, limit, func(beforeAt time.Time, beforeID int) ([]*models.Connection, error) {
+		return s.followRepo.ListFollowers(ctx, userID, userID, models.FollowPending, beforeAt, beforeID, limit+1)
+	})
+}
+
+// checkCanViewConnections hides the lists of a private profile from anyone
+// but the owner and their accepted followers.
+func (s *FollowService) checkCanViewConnections(ctx context.Context, viewerID, userID int) error {
+	if viewerID == userID {
+		return nil
+	}
+
+	profile, err := s.userRepo.GetPublicProfile(ctx, viewerID, userID)
+	if err != nil {
+		return err
+	}
+	if !profile.IsPrivate {
+		return nil
+	}
+
+	status, err := s.followRepo.GetStatus(ctx, viewerID, userID)
+	if err != nil {
+		return err
+	}
+	if status != models.FollowAccepted {
+		return Forbidden("this profile is private")
+	}
+	return nil
+}
+
+// page decodes the cursor, fetches one extra row to detect whether another
+// page exists and encodes the cursor for it.
+func (s *FollowService) page(after string, limit int, fetch func(time.Time, int) ([]*models.Connection, error)) (*models.ConnectionPage, error) {
+	var beforeAt time.Time
+	var beforeID int
+	if after != "" {
+		var err error
+		if beforeAt, beforeID, err = cursor.Decode(after); err != nil {
+			return nil, InvalidField("cursor", "%s", err)
+		}
+	}
+
+	items, err := fetch(beforeAt, beforeID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ConnectionPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = cursor.Encode(last.Since, last.ID)
+	}
+
+	return page, nil
+}
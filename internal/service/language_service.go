@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+	"windsurf-project/pkg/validator"
+)
+
+const maxDeclaredLanguages = 10
+
+type LanguageService struct {
+	languageRepo *repository.LanguageRepository
+}
+
+func NewLanguageService(languageRepo *repository.LanguageRepository) *LanguageService {
+	return &LanguageService{languageRepo: languageRepo}
+}
+
+func (s *LanguageService) GetProfile(ctx context.Context, userID int) (*models.LanguageProfile, error) {
+	return s.languageRepo.GetProfile(ctx, userID)
+}
+
+func (s *LanguageService) UpdateProfile(ctx context.Context, userID int, profile *models.LanguageProfile) (*models.LanguageProfile, error) {
+	if profile.Timezone != nil && *profile.Timezone != "" {
+		if _, err := time.LoadLocation(*profile.Timezone); err != nil {
+			return nil, InvalidField("timezone", "invalid timezone %q", *profile.Timezone)
+		}
+	} else {
+		profile.Timezone = nil
+	}
+
+	if len(profile.Speaks)+len(profile.Learning) > maxDeclaredLanguages {
+		return nil, InvalidField("languages", "at most %d languages can be declared", maxDeclaredLanguages)
+	}
+	if profile.Speaks == nil {
+		profile.Speaks = []models.UserLanguage{}
+	}
+	if profile.Learning == nil {
+		profile.Learning = []models.UserLanguage{}
+	}
+
+	for _, group := range [][]models.UserLanguage{profile.Speaks, profile.Learning} {
+		seen := make(map[string]bool)
+		for _, lang := range group {
+			if err := validator.ValidateLanguageCode(lang.Language); err != nil {
+				return nil, InvalidField("language", "%s", err)
+			}
+			if err := validator.ValidateCEFRLevel(lang.Level); err != nil {
+				return nil, InvalidField("level", "%s", err)
+			}
+			if seen[lang.Language] {
+				return nil, InvalidField("language", "language %q is listed more than once", lang.Language)
+			}
+			seen[lang.Language] = true
+		}
+	}
+
+	if err := s.languageRepo.ReplaceProfile(ctx, userID, profile); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *LanguageService) FindPartners(ctx context.Context, userID, limit, offset int) ([]*models.LanguagePartner, error) {
+	return s.languageRepo.FindPartners(ctx, userID, limit, offset)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+)
+
+// liveTicketTTL only has to cover the gap between fetching a ticket and
+// opening the connection.
+const liveTicketTTL = 30 * time.Second
+
+// LiveTicketService issues the one-time tickets that browsers pass in the
+// query string of WebSocket and EventSource URLs, which cannot carry an
+// Authorization header. Unlike a JWT, a ticket that ends up in a log or
+// browser history is useless: it expires within seconds and works once.
+type LiveTicketService struct {
+	users      repository.UserStore
+	ticketRepo *repository.LiveTicketRepository
+}
+
+func NewLiveTicketService(users repository.UserStore, ticketRepo *repository.LiveTicketRepository) *LiveTicketService {
+	return &LiveTicketService{users: users, ticketRepo: ticketRepo}
+}
+
+// Issue creates a ticket for userID.
+func (s *LiveTicketService) Issue(ctx context.Context, userID int) (*models.LiveTicket, error) {
+	ticketBytes := make([]byte, 32)
+	if _, err := rand.Read(ticketBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate ticket: %w", err)
+	}
+	ticket := hex.EncodeToString(ticketBytes)
+
+	if err := s.ticketRepo.Create(ctx, userID, hashTicket(ticket), liveTicketTTL); err != nil {
+		return nil, err
+	}
+	return &models.LiveTicket{Ticket: ticket, ExpiresIn: int(liveTicketTTL.Seconds())}, nil
+}
+
+// Redeem consumes ticket and returns the user it was issued to. Like a JWT,
+// a ticket is refused once the account is deactivated or its tokens were
+// revoked after the ticket was issued.
+func (s *LiveTicketService) Redeem(ctx context.Context, ticket string) (int, error) {
+	userID, expiresAt, err := s.ticketRepo.Consume(ctx, hashTicket(ticket))
+	if errors.Is(err, ErrNotFound) {
+		return 0, Unauthorized("invalid or expired ticket")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	isActive, revokedAt, err := s.users.GetTokenState(ctx, userID)
+	if errors.Is(err, ErrNotFound) {
+		return 0, Unauthorized("invalid or expired ticket")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check ticket: %w", err)
+	}
+	if !isActive {
+		return 0, Forbidden("account is deactivated")
+	}
+	if issuedAt := expiresAt.Add(-liveTicketTTL); revokedAt != nil && !issuedAt.After(*revokedAt) {
+		return 0, Unauthorized("ticket has been revoked")
+	}
+
+	return userID, nil
+}
+
+func hashTicket(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+	return hex.EncodeToString(sum[:])
+}
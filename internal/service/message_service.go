@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+	"windsurf-project/pkg/cursor"
+)
+
+const maxMessageLength = 4000
+
+// RealtimePublisher pushes events to the live connections of users.
+type RealtimePublisher interface {
+	PublishToUsers(userIDs []int, eventType string, data interface{}) error
+}
+
+// ErrFirstContactLimit is returned when a user has started too many
+// conversations with strangers in the last 24 hours.
+var ErrFirstContactLimit error = &Error{
+	Kind:    ErrRateLimited,
+	Code:    "first_contact_limit",
+	Message: "too many new conversations with people you don't know, try again later",
+}
+
+type MessageService struct {
+	userRepo          *repository.UserRepository
+	followRepo        *repository.FollowRepository
+	blockRepo         *repository.BlockRepository
+	messageRepo       *repository.MessageRepository
+	publisher         RealtimePublisher
+	firstContactLimit int
+}
+
+func NewMessageService(
+	userRepo *repository.UserRepository,
+	followRepo *repository.FollowRepository,
+	blockRepo *repository.BlockRepository,
+	messageRepo *repository.MessageRepository,
+	publisher RealtimePublisher,
+	firstContactLimit int,
+) *MessageService {
+	return &MessageService{
+		userRepo:          userRepo,
+		followRepo:        followRepo,
+		blockRepo:         blockRepo,
+		messageRepo:       messageRepo,
+		publisher:         publisher,
+		firstContactLimit: firstContactLimit,
+	}
+}
+
+// SendToUser sends a message to another member, starting a conversation if
+// needed.
+func (s *MessageService) SendToUser(ctx context.Context, senderID, recipientID int, body string) (*models.Message, error) {
+	if senderID == recipientID {
+		return nil, Validation("you cannot message yourself")
+	}
+
+	conversationID, err := s.messageRepo.FindConversation(ctx, senderID, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if conversationID == 0 {
+		body, err = normalizeMessageBody(body)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkCanMessage(ctx, senderID, recipientID); err != nil {
+			return nil, err
+		}
+
+		stranger, err := s.isStranger(ctx, senderID, recipientID)
+		if err != nil {
+			return nil, err
+		}
+		var quota *repository.FirstContactQuota
+		if stranger {
+			quota = &repository.FirstContactQuota{Since: time.Now().Add(-24 * time.Hour), Limit: s.firstContactLimit}
+		}
+
+		conversationID, err = s.messageRepo.CreateConversation(ctx, senderID, recipientID, quota)
+		if errors.Is(err, repository.ErrFirstContactQuota) {
+			return nil, ErrFirstContactLimit
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Send(ctx, senderID, conversationID, body)
+}
+
+// Send posts a message to an existing conversation.
+func (s *MessageService) Send(ctx context.Context, senderID, conversationID int, body string) (*models.Message, error) {
+	body, err := normalizeMessageBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientID, err := s.messageRepo.GetOtherParticipant(ctx, conversationID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanMessage(ctx, senderID, recipientID); err != nil {
+		return nil, err
+	}
+
+	msg := &models.Message{
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Body:           body,
+	}
+	if err := s.messageRepo.CreateMessage(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	// The sender is included so their other devices stay in sync.
+	if err := s.publisher.PublishToUsers([]int{recipientID, senderID}, "message.new", msg); err != nil {
+		log.Printf("messages: failed to publish message %d: %v", msg.ID, err)
+	}
+
+	return msg, nil
+}
+
+// Typing tells the other participant of a conversation that userID is
+// typing.
+func (s *MessageService) Typing(ctx context.Context, userID, conversationID int) error {
+	otherID, err := s.messageRepo.GetOtherParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	blocked, err := s.blockRepo.IsBlocked(ctx, userID, otherID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return NotFound("conversation not found")
+	}
+
+	return s.publisher.PublishToUsers([]int{otherID}, "typing", map[string]int{
+		"conversation_id": conversationID,
+		"user_id":         userID,
+	})
+}
+
+func (s *MessageService) ListConversations(ctx context.Context, userID int, after string, limit int) (*models.ConversationPage, error) {
+	var beforeAt time.Time
+	var beforeID int
+	if after != "" {
+		var err error
+		if beforeAt, beforeID, err = cursor.Decode(after); err != nil {
+			return nil, InvalidField("cursor", "%s", err)
+		}
+	}
+
+	items, err := s.messageRepo.ListConversations(ctx, userID, beforeAt, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ConversationPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = cursor.Encode(last.LastMessageAt, last.ID)
+	}
+
+	return page, nil
+}
+
+func (s *MessageService) ListMessages(ctx context.Context, userID, conversationID int, after string, limit int) (*models.MessagePage, error) {
+	otherID, err := s.messageRepo.GetOtherParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockRepo.IsBlocked(ctx, userID, otherID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, NotFound("conversation not found")
+	}
+
+	var beforeID int64
+	if after != "" {
+		_, id, err := cursor.Decode(after)
+		if err != nil {
+			return nil, InvalidField("cursor", "%s", err)
+		}
+		beforeID = int64(id)
+	}
+
+	items, err := s.messageRepo.ListMessages(ctx, conversationID, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	otherLastRead, err := s.messageRepo.GetLastRead(ctx, conversationID, otherID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.MessagePage{Items: items, OtherLastReadMessageID: otherLastRead}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = cursor.Encode(last.CreatedAt, int(last.ID))
+	}
+
+	return page, nil
+}
+
+func (s *MessageService) MarkRead(ctx context.Context, userID, conversationID int, upToID *int64) error {
+	if _, err := s.messageRepo.GetOtherParticipant(ctx, conversationID, userID); err != nil {
+		return err
+	}
+	return s.messageRepo.MarkRead(ctx, conversationID, userID, upToID)
+}
+
+func (s *MessageService) UnreadCount(ctx context.Context, userID int) (int, error) {
+	return s.messageRepo.UnreadCount(ctx, userID)
+}
+
+func (s *MessageService) GetSettings(ctx context.Context, userID int) (*models.MessagingSettings, error) {
+	return s.userRepo.GetMessagingSettings(ctx, userID)
+}
+
+func (s *MessageService) UpdateSettings(ctx context.Context, userID int, settings *models.MessagingSettings) error {
+	if settings.MessagesFrom != models.MessagesFromEveryone && settings.MessagesFrom != models.MessagesFromFollowing {
+		return InvalidField("messages_from", "messages_from must be %q or %q", models.MessagesFromEveryone, models.MessagesFromFollowing)
+	}
+	return s.userRepo.UpdateMessagingSettings(ctx, userID, settings)
+}
+
+// checkCanMessage enforces blocks and the recipient's "only people I follow"
+// setting. A block is reported as a missing user so it is not revealed.
+func (s *MessageService) checkCanMessage(ctx context.Context, senderID, recipientID int) error {
+	blocked, err := s.blockRepo.IsBlocked(ctx, senderID, recipientID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return NotFound("user not found")
+	}
+
+	settings, err := s.userRepo.GetMessagingSettings(ctx, recipientID)
+	if err != nil {
+		return err
+	}
+	if settings.MessagesFrom == models.MessagesFromFollowing {
+		status, err := s.followRepo.GetStatus(ctx, recipientID, senderID)
+		if err != nil {
+			return err
+		}
+		if status != models.FollowAccepted {
+			return Forbidden("this member only accepts messages from people they follow")
+		}
+	}
+
+	return nil
+}
+
+// isStranger reports whether neither user follows the other.
+func (s *MessageService) isStranger(ctx context.Context, userA, userB int) (bool, error) {
+	for _, pair := range [][2]int{{userA, userB}, {userB, userA}} {
+		status, err := s.followRepo.GetStatus(ctx, pair[0], pair[1])
+		if err != nil {
+			return false, err
+		}
+		if status == models.FollowAccepted {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func normalizeMessageBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", InvalidField("body", "message body is required")
+	}
+	if len(body) > maxMessageLength {
+		return "", InvalidField("body", "message must not exceed %d characters", maxMessageLength)
+	}
+	return body, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+)
+
+const maxReportDetailsLength = 2000
+
+// LiveConnections closes the realtime connections of a user.
+type LiveConnections interface {
+	DisconnectUser(userID int) error
+}
+
+type ModerationService struct {
+	userRepo     *repository.UserRepository
+	reportRepo   *repository.ReportRepository
+	emailService *EmailService
+	live         LiveConnections
+}
+
+func NewModerationService(userRepo *repository.UserRepository, reportRepo *repository.ReportRepository, emailService *EmailService, live LiveConnections) *ModerationService {
+	return &ModerationService{
+		userRepo:     userRepo,
+		reportRepo:   reportRepo,
+		emailService: emailService,
+		live:         live,
+	}
+}
+
+// CreateReport files a report. Only members can be reported for now; posts,
+// comments and events will become reportable once they exist.
+func (s *ModerationService) CreateReport(ctx context.Context, reporterID int, req *models.CreateReportRequest) (*models.Report, error) {
+	if req.TargetType != models.ReportTargetUser {
+		return nil, InvalidField("target_type", "unsupported target type %q", req.TargetType)
+	}
+	if !isReportReason(req.Reason) {
+		return nil, InvalidField("reason", "invalid reason %q", req.Reason)
+	}
+	if req.Details != nil && len(*req.Details) > maxReportDetailsLength {
+		return nil, InvalidField("details", "details must not exceed %d characters", maxReportDetailsLength)
+	}
+	if req.TargetID == reporterID {
+		return nil, Validation("you cannot report yourself")
+	}
+	if _, err := s.userRepo.GetByID(ctx, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	report := &models.Report{
+		ReporterID: reporterID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+	}
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return nil, Conflict("you have already reported this")
+		}
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *ModerationService) ListReports(ctx context.Context, status string, limit, offset int) ([]*models.Report, error) {
+	if status == "" {
+		status = models.ReportOpen
+	}
+	switch status {
+	case models.ReportOpen, models.ReportInReview, models.ReportResolved, models.ReportDismissed:
+	default:
+		return nil, InvalidField("status", "invalid status %q", status)
+	}
+	return s.reportRepo.List(ctx, status, limit, offset)
+}
+
+func (s *ModerationService) GetReport(ctx context.Context, id int) (*models.Report, error) {
+	return s.reportRepo.GetByID(ctx, id)
+}
+
+// AssignReport assigns a report to a moderator, the caller by default.
+func (s *ModerationService) AssignReport(ctx context.Context, id, moderatorID int, req *models.AssignReportRequest) (*models.Report, error) {
+	assigneeID := moderatorID
+	if req.AssigneeID != nil {
+		assigneeID = *req.AssigneeID
+	}
+
+	isAdmin, err := s.userRepo.IsAdmin(ctx, assigneeID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, InvalidField("assignee_id", "reports can only be assigned to admins")
+	}
+
+	if err := s.reportRepo.Assign(ctx, id, assigneeID); err != nil {
+		return nil, err
+	}
+	return s.reportRepo.GetByID(ctx, id)
+}
+
+// ResolveReport closes a report as resolved or dismissed.
+func (s *ModerationService) ResolveReport(ctx context.Context, id int, req *models.ResolveReportRequest) (*models.Report, error) {
+	if req.Status != models.ReportResolved && req.Status != models.ReportDismissed {
+		return nil, InvalidField("status", "status must be %q or %q", models.ReportResolved, models.ReportDismissed)
+	}
+
+	if err := s.reportRepo.Close(ctx, id, req.Status, req.Note); err != nil {
+		return nil, err
+	}
+	return s.reportRepo.GetByID(ctx, id)
+}
+
+// TakeAction applies a moderation action to the reported member of an open
+// report and records it. Suspending deactivates the account, revokes its
+// tokens and closes its open WebSocket and SSE connections. Administrators cannot be suspended, and there is no API to
+// reinstate a suspended account.
+func (s *ModerationService) TakeAction(ctx context.Context, reportID, moderatorID int, req *models.ModerationActionRequest) (*models.ModerationAction, error) {
+	switch req.Action {
+	case models.ModerationWarn, models.ModerationSuspend, models.ModerationDeleteContent:
+	default:
+		return nil, InvalidField("action", "invalid action %q", req.Action)
+	}
+
+	report, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportOpen && report.Status != models.ReportInReview {
+		return nil, Conflict("report is already %s", report.Status)
+	}
+
+	target, err := s.userRepo.GetByID(ctx, report.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	action := &models.ModerationAction{
+		ReportID:     &report.ID,
+		ModeratorID:  moderatorID,
+		TargetUserID: target.ID,
+		Action:       req.Action,
+		Note:         req.Note,
+	}
+	if err := s.reportRepo.ApplyAction(ctx, action); err != nil {
+		if errors.Is(err, repository.ErrTargetIsAdmin) {
+			return nil, Forbidden("administrators cannot be suspended")
+		}
+		return nil, err
+	}
+
+	if req.Action == models.ModerationSuspend {
+		// The suspension is committed; a connection left open is logged
+		// rather than reported as a failed action.
+		if err := s.live.DisconnectUser(target.ID); err != nil {
+			log.Printf("moderation: user %d: failed to close live connections: %v", target.ID, err)
+		}
+	}
+
+	if req.Action == models.ModerationWarn {
+		s.emailService.SendAsync(func() error {
+			return s.emailService.SendModerationWarning(target.Email, target.Username, req.Note)
+		})
+	}
+
+	return action, nil
+}
+
+func isReportReason(reason string) bool {
+	for _, r := range models.ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
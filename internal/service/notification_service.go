@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+	"windsurf-project/pkg/cursor"
+)
+
+type NotificationService struct {
+	userRepo         *repository.UserRepository
+	blockRepo        *repository.BlockRepository
+	notificationRepo *repository.NotificationRepository
+	emailService     *EmailService
+	publisher        RealtimePublisher
+}
+
+func NewNotificationService(
+	userRepo *repository.UserRepository,
+	blockRepo *repository.BlockRepository,
+	notificationRepo *repository.NotificationRepository,
+	emailService *EmailService,
+	publisher RealtimePublisher,
+) *NotificationService {
+	return &NotificationService{
+		userRepo:         userRepo,
+		blockRepo:        blockRepo,
+		notificationRepo: notificationRepo,
+		emailService:     emailService,
+		publisher:        publisher,
+	}
+}
+
+// Notify delivers a notification to userID. actorID is the member who
+// caused it (0 for none) and entityType/entityID point at the subject, if
+// any. The recipient's per-type preferences decide whether it is stored in
+// the notification center, emailed, or both. Notifications from blocked or
+// muted actors are dropped. Failures are logged rather than returned so
+// callers never fail because a notification could not be delivered.
+func (s *NotificationService) Notify(ctx context.Context, userID int, notificationType string, actorID int, entityType string, entityID int) {
+	if err := s.notify(ctx, userID, notificationType, actorID, entityType, entityID); err != nil {
+		log.Printf("notifications: %s for user %d: %v", notificationType, userID, err)
+	}
+}
+
+func (s *NotificationService) notify(ctx context.Context, userID int, notificationType string, actorID int, entityType string, entityID int) error {
+	if actorID == userID {
+		return nil
+	}
+
+	var actor *models.User
+	if actorID != 0 {
+		blocked, err := s.blockRepo.IsBlocked(ctx, userID, actorID)
+		if err != nil {
+			return err
+		}
+		muted, err := s.blockRepo.IsMuted(ctx, userID, actorID)
+		if err != nil {
+			return err
+		}
+		if blocked || muted {
+			return nil
+		}
+		if actor, err = s.userRepo.GetByID(ctx, actorID); err != nil {
+			return err
+		}
+	}
+
+	pref, err := s.preference(ctx, userID, notificationType)
+	if err != nil {
+		return err
+	}
+
+	n := &models.Notification{UserID: userID, Type: notificationType}
+	if actor != nil {
+		n.Actor = &models.Connection{
+			ID:        actor.ID,
+			Username:  actor.Username,
+			FirstName: actor.FirstName,
+			LastName:  actor.LastName,
+			AvatarURL: actor.AvatarURL,
+		}
+	}
+	if entityType != "" {
+		n.EntityType = &entityType
+		n.EntityID = &entityID
+	}
+
+	if pref.InApp {
+		if err := s.notificationRepo.Create(ctx, n); err != nil {
+			return err
+		}
+		if n.Actor != nil {
+			n.Actor.Since = n.CreatedAt
+		}
+		s.publish(userID, "notification.new", n)
+		s.publishUnreadCount(ctx, userID)
+	}
+
+	if pref.Email {
+		recipient, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		s.emailService.SendAsync(func() error {
+			return s.emailService.SendNotificationEmail(recipient.Email, recipient.Username, summarize(n))
+		})
+	}
+
+	return nil
+}
+
+func (s *NotificationService) List(ctx context.Context, userID int, after string, unreadOnly bool, limit int) (*models.NotificationPage, error) {
+	var beforeID int64
+	if after != "" {
+		_, id, err := cursor.Decode(after)
+		if err != nil {
+			return nil, InvalidField("cursor", "%s", err)
+		}
+		beforeID = int64(id)
+	}
+
+	items, err := s.notificationRepo.List(ctx, userID, beforeID, unreadOnly, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepo.UnreadCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.NotificationPage{Items: items, UnreadCount: unread}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = cursor.Encode(last.CreatedAt, int(last.ID))
+	}
+
+	return page, nil
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, userID int) (int, error) {
+	return s.notificationRepo.UnreadCount(ctx, userID)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID int, id int64) error {
+	ok, err := s.notificationRepo.MarkRead(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NotFound("notification not found")
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int) error {
+	if err := s.notificationRepo.MarkAllRead(ctx, userID); err != nil {
+		return err
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
+}
+
+// GetPreferences returns the user's preference for every notification type,
+// filling in defaults for types they have not configured.
+func (s *NotificationService) GetPreferences(ctx context.Context, userID int) ([]models.NotificationPreference, error) {
+	saved, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make([]models.NotificationPreference, 0, len(models.NotificationDefaults))
+	for _, def := range models.NotificationDefaults {
+		if p, ok := saved[def.Type]; ok {
+			prefs = append(prefs, p)
+		} else {
+			prefs = append(prefs, def)
+		}
+	}
+	return prefs, nil
+}
+
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID int, prefs []models.NotificationPreference) ([]models.NotificationPreference, error) {
+	for _, p := range prefs {
+		if _, ok := defaultPreference(p.Type); !ok {
+			return nil, InvalidField("type", "unknown notification type %q", p.Type)
+		}
+	}
+
+	if err := s.notificationRepo.SavePreferences(ctx, userID, prefs); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+func (s *NotificationService) preference(ctx context.Context, userID int, notificationType string) (models.NotificationPreference, error) {
+	def, ok := defaultPreference(notificationType)
+	if !ok {
+		return def, Validation("unknown notification type %q", notificationType)
+	}
+
+	saved, err := s.notificationRepo.GetPreferences(ctx, userID)
+	if err != nil {
+		return def, err
+	}
+	if p, ok := saved[notificationType]; ok {
+		return p, nil
+	}
+	return def, nil
+}
+
+func (s *NotificationService) publishUnreadCount(ctx context.Context, userID int) {
+	count, err := s.notificationRepo.UnreadCount(ctx, userID)
+	if err != nil {
+		log.Printf("notifications: %v", err)
+		return
+	}
+	s.publish(userID, "notification.unread", map[string]int{"unread_count": count})
+}
+
+func (s *NotificationService) publish(userID int, eventType string, data interface{}) {
+	if err := s.publisher.PublishToUsers([]int{userID}, eventType, data); err != nil {
+		log.Printf("notifications: failed to publish %s: %v", eventType, err)
+	}
+}
+
+func defaultPreference(notificationType string) (models.NotificationPreference, bool) {
+	for _, def := range models.NotificationDefaults {
+		if def.Type == notificationType {
+			return def, true
+		}
+	}
+	return models.NotificationPreference{}, false
+}
+
+// summarize renders a one-line description used as the email subject.
+func summarize(n *models.Notification) string {
+	actor := "Someone"
+	if n.Actor != nil {
+		actor = n.Actor.Username
+	}
+
+	switch n.Type {
+	case models.NotificationNewFollower:
+		return actor + " started following you"
+	case models.NotificationFollowRequest:
+		return actor + " asked to follow you"
+	case models.NotificationRSVPPromoted:
+		return "A spot opened up and you are now attending"
+	case models.NotificationCommentReply:
+		return actor + " replied to your comment"
+	}
+	return "You have a new notification"
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"windsurf-project/internal/lifecycle"
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+)
+
+const (
+	recommendationBatchSize = 200
+	recommendationsPerUser  = 50
+)
+
+// errBatchRunning means another replica holds the recomputation lock.
+var errBatchRunning = errors.New("recommendations are being recomputed by another instance")
+
+type RecommendationService struct {
+	recRepo *repository.RecommendationRepository
+}
+
+func NewRecommendationService(recRepo *repository.RecommendationRepository) *RecommendationService {
+	return &RecommendationService{recRepo: recRepo}
+}
+
+// List returns the cached recommendations for a user. Results are refreshed by
+// Run, so the request path never scores candidates itself.
+func (s *RecommendationService) List(ctx context.Context, userID, limit, offset int) ([]*models.Recommendation, error) {
+	return s.recRepo.List(ctx, userID, limit, offset)
+}
+
+// RecomputeAll refreshes the cached recommendations of every eligible user
+// and drops those of users who are no longer eligible. Only one replica runs
+// it at a time; on the others it returns errBatchRunning.
+func (s *RecommendationService) RecomputeAll(ctx context.Context) error {
+	ran, err := s.recRepo.WithBatchLock(ctx, func() error { return s.recomputeAll(ctx) })
+	if err != nil {
+		return err
+	}
+	if !ran {
+		return errBatchRunning
+	}
+	return nil
+}
+
+func (s *RecommendationService) recomputeAll(ctx context.Context) error {
+	afterID := 0
+	for {
+		ids, err := s.recRepo.ListUserIDs(ctx, afterID, recommendationBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := s.recRepo.Recompute(ctx, id, recommendationsPerUser); err != nil {
+				log.Printf("recommendations: user %d: %v", id, err)
+			}
+		}
+		if len(ids) < recommendationBatchSize {
+			return s.recRepo.PurgeIneligible(ctx)
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// Run recomputes recommendations immediately and then on every interval until
+// stop is closed.
+func (s *RecommendationService) Run(interval time.Duration, stop <-chan struct{}) {
+	ctx, cancel := lifecycle.StopContext(stop)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		switch err := s.RecomputeAll(ctx); {
+		case errors.Is(err, errBatchRunning):
+			// Another replica is refreshing them.
+		case err != nil:
+			log.Printf("recommendations: %v", err)
+		default:
+			log.Printf("recommendations: refreshed in %s", time.Since(start))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
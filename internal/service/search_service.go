@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+)
+
+const (
+	minSearchLength = 2
+	maxSearchLength = 200
+)
+
+type SearchService struct {
+	searchRepo *repository.SearchRepository
+}
+
+func NewSearchService(searchRepo *repository.SearchRepository) *SearchService {
+	return &SearchService{searchRepo: searchRepo}
+}
+
+// Search finds members and interest groups. lang selects the stemming
+// language ("en", "es", "fr" or "de"); when empty all of them are tried.
+// resultType optionally restricts results to "member" or "group".
+func (s *SearchService) Search(ctx context.Context, viewerID int, text, lang, resultType string, limit, offset int) (*models.SearchResponse, error) {
+	text = strings.TrimSpace(text)
+	if n := utf8.RuneCountInString(text); n < minSearchLength || n > maxSearchLength {
+		return nil, InvalidField("q", "q must be between %d and %d characters", minSearchLength, maxSearchLength)
+	}
+
+	config := ""
+	if lang != "" {
+		var ok bool
+		if config, ok = models.SearchLanguages[lang]; !ok {
+			return nil, InvalidField("lang", "lang must be one of en, es, fr, de")
+		}
+	}
+
+	if resultType != "" && resultType != models.SearchTypeMember && resultType != models.SearchTypeGroup {
+		return nil, InvalidField("type", "type must be %q or %q", models.SearchTypeMember, models.SearchTypeGroup)
+	}
+
+	results, err := s.searchRepo.Search(ctx, viewerID, text, config, resultType, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range results {
+		res.Snippet = highlightSnippet(res.Snippet)
+	}
+	facets, err := s.searchRepo.Facets(ctx, viewerID, text, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SearchResponse{Query: text, Results: results, Facets: facets}, nil
+}
+
+// highlightSnippet HTML-escapes a snippet from the repository, whose text
+// is written by members, and turns its match markers into <mark> tags, so
+// clients can render it as HTML.
+func highlightSnippet(snippet string) string {
+	var b strings.Builder
+	open := false
+	for snippet != "" {
+		i := strings.IndexAny(snippet, repository.HighlightStart+repository.HighlightStop)
+		if i < 0 {
+			b.WriteString(html.EscapeString(snippet))
+			break
+		}
+		b.WriteString(html.EscapeString(snippet[:i]))
+
+		switch start := snippet[i:i+1] == repository.HighlightStart; {
+		case start && !open:
+			b.WriteString("<mark>")
+			open = true
+		case !start && open:
+			b.WriteString("</mark>")
+			open = false
+		}
+		snippet = snippet[i+1:]
+	}
+	if open {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
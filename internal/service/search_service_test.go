@@ -0,0 +1,20 @@
+package service
+
+import "testing"
+
+func TestHighlightSnippetEscapesText(t *testing.T) {
+	tests := []struct {
+		name, snippet, want string
+	}{
+		{"plain", "Street \x02photographer\x03 in Lisbon", "Street <mark>photographer</mark> in Lisbon"},
+		{"markup in bio", "<img src=x onerror=alert(1)> \x02photo\x03", "&lt;img src=x onerror=alert(1)&gt; <mark>photo</mark>"},
+		{"escaped inside match", "\x02<b>\x03", "<mark>&lt;b&gt;</mark>"},
+		{"unbalanced markers", "\x03a \x02b \x02c", "a <mark>b c</mark>"},
+	}
+
+	for _, tt := range tests {
+		if got := highlightSnippet(tt.snippet); got != tt.want {
+			t.Errorf("%s: highlightSnippet(%q) = %q, want %q", tt.name, tt.snippet, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"windsurf-project/internal/models"
+	"windsurf-project/internal/repository"
+	"windsurf-project/pkg/validator"
+)
+
+const (
+	// coordinatePrecision rounds stored coordinates to two decimal places,
+	// roughly one kilometre, so precise positions are never persisted.
+	coordinatePrecision = 100
+	maxNearbyRadiusKM   = 200
+)
+
+type UserService struct {
+	userRepo *repository.UserRepository
+}
+
+func NewUserService(userRepo *repository.UserRepository) *UserService {
+	return &UserService{userRepo: userRepo}
+}
+
+func (s *UserService) UpdateLocation(ctx context.Context, userID int, req *models.UpdateLocationRequest) (*models.UserLocation, error) {
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, InvalidField("latitude", "latitude and longitude must be provided together")
+	}
+
+	loc := &models.UserLocation{
+		City:          req.City,
+		ShareLocation: req.ShareLocation,
+	}
+
+	if req.Latitude != nil {
+		if err := validator.ValidateCoordinates(*req.Latitude, *req.Longitude); err != nil {
+			return nil, InvalidField("latitude", "%s", err)
+		}
+		lat := coarsen(*req.Latitude)
+		lng := coarsen(*req.Longitude)
+		loc.Latitude = &lat
+		loc.Longitude = &lng
+	}
+
+	if err := s.userRepo.UpdateLocation(ctx, userID, loc); err != nil {
+		return nil, err
+	}
+
+	return loc, nil
+}
+
+func (s *UserService) GetLocation(ctx context.Context, userID int) (*models.UserLocation, error) {
+	return s.userRepo.GetLocation(ctx, userID)
+}
+
+// FindNearby searches around the caller's own stored location so clients
+// cannot probe arbitrary points to triangulate other members. Only members
+// who share their location may search, so nobody sees others while staying
+// invisible themselves.
+func (s *UserService) FindNearby(ctx context.Context, userID int, radiusKM float64, limit, offset int) ([]*models.NearbyUser, error) {
+	// NaN fails every comparison, so it is rejected explicitly.
+	if math.IsNaN(radiusKM) || math.IsInf(radiusKM, 0) || radiusKM < 1 || radiusKM > maxNearbyRadiusKM {
+		return nil, InvalidField("radius", "radius must be between 1 and %d km", maxNearbyRadiusKM)
+	}
+
+	loc, err := s.userRepo.GetLocation(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if loc.Latitude == nil || loc.Longitude == nil {
+		return nil, Validation("set your location before searching nearby members")
+	}
+	if !loc.ShareLocation {
+		return nil, Forbidden("share your location to search nearby members")
+	}
+
+	return s.userRepo.FindNearby(ctx, userID, *loc.Latitude, *loc.Longitude, radiusKM, limit, offset)
+}
+
+func coarsen(v float64) float64 {
+	return math.Round(v*coordinatePrecision) / coordinatePrecision
+}
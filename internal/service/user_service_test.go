@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFindNearbyRejectsInvalidRadius(t *testing.T) {
+	// The radius is checked before any store is used.
+	s := &UserService{}
+
+	for _, km := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 0, 0.5, -3, 200.5} {
+		_, err := s.FindNearby(context.Background(), 1, km, 20, 0)
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("FindNearby(km=%v) = %v, want a validation error", km, err)
+		}
+	}
+}
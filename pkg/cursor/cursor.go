@@ -0,0 +1,42 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encode builds an opaque keyset cursor from the sort timestamp and ID of the
+// last item on a page.
+func Encode(t time.Time, id int) string {
+	raw := fmt.Sprintf("%d:%d", t.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode parses a cursor produced by Encode. The time is returned in UTC,
+// the zone lib/pq reads TIMESTAMP columns in, so that comparing it with
+// such a column compares the same wall-clock time whatever the local zone.
+func Decode(s string) (time.Time, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid cursor")
+	}
+
+	return time.Unix(0, nanos).UTC(), id, nil
+}
@@ -0,0 +1,35 @@
+package cursor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoundTripKeepsWallClockInUTC(t *testing.T) {
+	// Decode must not depend on the zone of the host.
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	defer func() { time.Local = local }()
+
+	// lib/pq reads TIMESTAMP columns as UTC.
+	at := time.Date(2024, 3, 1, 12, 30, 45, 123456000, time.UTC)
+
+	gotAt, gotID, err := Decode(Encode(at, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotID != 42 {
+		t.Errorf("id = %d, want 42", gotID)
+	}
+	if !gotAt.Equal(at) || gotAt.Location() != time.UTC || gotAt.Hour() != 12 {
+		t.Errorf("time = %v, want %v", gotAt, at)
+	}
+}
+
+func TestDecodeRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"", "!!!", Encode(time.Now(), 1)[:3]} {
+		if _, _, err := Decode(s); err == nil {
+			t.Errorf("Decode(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package response
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Code is a stable,
+// machine-readable identifier of the failure that clients can switch on
+// instead of matching Detail, which is meant for people and may change.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes one invalid field of a request body or query.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// WriteProblem writes p as application/problem+json when the client accepts
+// it, and otherwise as the classic envelope, which carries Detail in
+// "error" and the code and field errors alongside it. Missing fields are
+// filled in from the status and request.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Code == "" {
+		p.Code = CodeForStatus(p.Status)
+	}
+
+	if !WantsProblem(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(p.Status)
+		json.NewEncoder(w).Encode(Response{
+			Success: false,
+			Error:   p.Detail,
+			Code:    p.Code,
+			Errors:  p.Errors,
+		})
+		return
+	}
+
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.Path
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// WantsProblem reports whether the request's Accept header asks for
+// application/problem+json. Clients that send nothing, application/json or
+// */* keep getting the envelope.
+func WantsProblem(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || mediaType != ProblemContentType {
+				continue
+			}
+			if q, ok := params["q"]; ok {
+				if v, err := strconv.ParseFloat(q, 64); err != nil || v == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// CodeForStatus returns the default code for a status, used when nothing
+// more specific is known.
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusTooManyRequests:
+		return "rate_limited"
+	case http.StatusGatewayTimeout:
+		return "timeout"
+	}
+	if status >= 500 {
+		return "internal_error"
+	}
+	return "error"
+}
@@ -6,9 +6,11 @@ import (
 )
 
 type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success bool         `json:"success"`
+	Data    interface{}  `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Code    string       `json:"code,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
 }
 
 func JSON(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -23,16 +25,14 @@ func JSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func Error(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	
-	response := Response{
-		Success: false,
-		Error:   message,
-	}
-	
-	json.NewEncoder(w).Encode(response)
+// Error writes an error with the default code for its status. See
+// WriteProblem for the format.
+func Error(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	WriteProblem(w, r, &Problem{
+		Status: statusCode,
+		Code:   CodeForStatus(statusCode),
+		Detail: message,
+	})
 }
 
 func Success(w http.ResponseWriter, data interface{}) {
@@ -0,0 +1,248 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError is one failed rule. Field is the JSON path of the field, e.g.
+// "email" or "speaks[1].level", and Rule the name of the rule that failed.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// Errors is every rule that failed on a value, in field order.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// rule checks a non-empty value. It returns the message to show when the
+// value is invalid, or "" when it is valid.
+type rule func(field string, v reflect.Value, param string) string
+
+var rules = map[string]rule{
+	"email":    ruleEmail,
+	"min":      ruleMin,
+	"max":      ruleMax,
+	"username": ruleUsername,
+	"password": rulePassword,
+	"e164":     ruleE164,
+	"url":      ruleURL,
+}
+
+// Struct checks the `validate` tags of v, which must be a struct, a slice of
+// structs or a pointer to either, and of every struct nested in it. Tags
+// list rules separated by commas, e.g. `validate:"required,min=3,max=100"`.
+// Only required rejects empty values; every other rule skips them, so an
+// optional field is one without required. Struct returns Errors holding
+// every failure, or nil.
+func Struct(v interface{}) error {
+	var errs Errors
+	walk(reflect.ValueOf(v), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func walk(v reflect.Value, path string, errs *Errors) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name := fieldName(sf)
+			if name == "-" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			field := v.Field(i)
+			if tag := sf.Tag.Get("validate"); tag != "" {
+				check(field, name, tag, errs)
+			}
+			walk(field, name, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+// check runs the rules of one tag against field.
+func check(field reflect.Value, name, tag string, errs *Errors) {
+	for field.Kind() == reflect.Ptr && !field.IsNil() {
+		field = field.Elem()
+	}
+
+	for _, spec := range strings.Split(tag, ",") {
+		ruleName, param, _ := strings.Cut(strings.TrimSpace(spec), "=")
+		if ruleName == "required" {
+			if isEmpty(field) {
+				*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Message: fmt.Sprintf("%s is required", name)})
+				// The other rules would only repeat that the field is missing.
+				return
+			}
+			continue
+		}
+
+		fn, ok := rules[ruleName]
+		if !ok {
+			panic(fmt.Sprintf("validator: unknown rule %q on field %s", ruleName, name))
+		}
+		if isEmpty(field) {
+			continue
+		}
+		if msg := fn(name, field, param); msg != "" {
+			*errs = append(*errs, FieldError{Field: name, Rule: ruleName, Message: msg})
+		}
+	}
+}
+
+// fieldName is the name a field has in JSON, so errors match the request.
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func ruleEmail(field string, v reflect.Value, _ string) string {
+	if !emailRegex.MatchString(v.String()) {
+		return fmt.Sprintf("%s must be a valid email address", field)
+	}
+	return ""
+}
+
+func ruleMin(field string, v reflect.Value, param string) string {
+	return bound(field, v, param, true)
+}
+
+func ruleMax(field string, v reflect.Value, param string) string {
+	return bound(field, v, param, false)
+}
+
+// bound checks min and max, which limit the length of strings in
+// characters, the number of items in slices and maps, and numbers by value.
+func bound(field string, v reflect.Value, param string, lower bool) string {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validator: invalid bound %q on field %s", param, field))
+	}
+
+	var n float64
+	var unit string
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		n, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		panic(fmt.Sprintf("validator: min and max do not apply to field %s of kind %s", field, v.Kind()))
+	}
+
+	switch {
+	case lower && n < limit:
+		if unit == "" {
+			return fmt.Sprintf("%s must be at least %s", field, param)
+		}
+		return fmt.Sprintf("%s must be at least %s%s long", field, param, unit)
+	case !lower && n > limit:
+		if unit == "" {
+			return fmt.Sprintf("%s must be at most %s", field, param)
+		}
+		return fmt.Sprintf("%s must not exceed %s%s", field, param, unit)
+	}
+	return ""
+}
+
+var usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ruleUsername applies the same limits as ValidateUsername.
+func ruleUsername(field string, v reflect.Value, _ string) string {
+	s := v.String()
+	switch {
+	case len(s) < 3:
+		return fmt.Sprintf("%s must be at least 3 characters long", field)
+	case len(s) > 100:
+		return fmt.Sprintf("%s must not exceed 100 characters", field)
+	case !usernameRegex.MatchString(s):
+		return fmt.Sprintf("%s can only contain letters, numbers, underscores, and hyphens", field)
+	}
+	return ""
+}
+
+// maxPasswordBytes is the most bcrypt accepts; longer passwords fail to hash.
+const maxPasswordBytes = 72
+
+func rulePassword(field string, v reflect.Value, _ string) string {
+	s := v.String()
+	switch {
+	case utf8.RuneCountInString(s) < 8:
+		return fmt.Sprintf("%s must be at least 8 characters long", field)
+	case len(s) > maxPasswordBytes:
+		return fmt.Sprintf("%s must not exceed %d bytes", field, maxPasswordBytes)
+	}
+	return ""
+}
+
+var e164Regex = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+func ruleE164(field string, v reflect.Value, _ string) string {
+	if !e164Regex.MatchString(v.String()) {
+		return fmt.Sprintf("%s must be a phone number in E.164 format, such as +14155552671", field)
+	}
+	return ""
+}
+
+func ruleURL(field string, v reflect.Value, _ string) string {
+	u, err := url.ParseRequestURI(v.String())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Sprintf("%s must be an http or https URL", field)
+	}
+	return ""
+}
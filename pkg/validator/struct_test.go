@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type contact struct {
+	Phone   string  `json:"phone" validate:"required,e164"`
+	Website *string `json:"website,omitempty" validate:"url"`
+}
+
+type signup struct {
+	Email    string    `json:"email" validate:"required,email"`
+	Username string    `json:"username" validate:"required,username"`
+	Password string    `json:"password" validate:"required,password"`
+	Age      int       `json:"age" validate:"min=13,max=120"`
+	Tags     []string  `json:"tags" validate:"max=2"`
+	Contacts []contact `json:"contacts"`
+	internal string    `validate:"required"`
+}
+
+func TestStructCollectsEveryFieldError(t *testing.T) {
+	site := "ftp://example.com"
+	err := Struct(&signup{
+		Email:    "not-an-email",
+		Username: "a!",
+		Password: "short",
+		Age:      7,
+		Tags:     []string{"a", "b", "c"},
+		Contacts: []contact{{Phone: "+14155552671"}, {Phone: "555-1234", Website: &site}},
+	})
+
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Struct() = %v, want Errors", err)
+	}
+	want := map[string]string{
+		"email":               "email",
+		"username":            "username",
+		"password":            "password",
+		"age":                 "min",
+		"tags":                "max",
+		"contacts[1].phone":   "e164",
+		"contacts[1].website": "url",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for _, fe := range errs {
+		if want[fe.Field] != fe.Rule {
+			t.Errorf("unexpected error %+v", fe)
+		}
+	}
+}
+
+func TestStructRequiredStopsOtherRules(t *testing.T) {
+	err := Struct(&signup{Username: "valid_name", Password: "long enough"})
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("Struct() = %v, want only the missing email", err)
+	}
+	if errs[0].Field != "email" || errs[0].Rule != "required" || errs[0].Message != "email is required" {
+		t.Errorf("error = %+v", errs[0])
+	}
+}
+
+func TestStructValid(t *testing.T) {
+	site := "https://example.com/me"
+	err := Struct(&signup{
+		Email:    "ada@example.com",
+		Username: "ada_l",
+		Password: "correct horse",
+		Contacts: []contact{{Phone: "+442071838750", Website: &site}},
+	})
+	if err != nil {
+		t.Errorf("Struct() = %v, want nil", err)
+	}
+}
+
+func TestPasswordRejectsWhatBcryptCannotHash(t *testing.T) {
+	err := Struct(&signup{
+		Email:    "ada@example.com",
+		Username: "ada_l",
+		Password: strings.Repeat("x", maxPasswordBytes+1),
+	})
+	if err == nil || !strings.Contains(err.Error(), "must not exceed 72 bytes") {
+		t.Errorf("Struct() = %v, want a length error", err)
+	}
+}
+
+func TestStructWalksSlices(t *testing.T) {
+	err := Struct(&[]contact{{Phone: "+14155552671"}, {}})
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "[1].phone" {
+		t.Errorf("Struct() = %v, want [1].phone required", err)
+	}
+}
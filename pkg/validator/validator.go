@@ -39,8 +39,7 @@ func ValidateUsername(username string) error {
 		return fmt.Errorf("username must not exceed 100 characters")
 	}
 	// Only allow alphanumeric, underscore, and hyphen
-	validUsername := regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
-	if !validUsername.MatchString(username) {
+	if !usernameRegex.MatchString(username) {
 		return fmt.Errorf("username can only contain letters, numbers, underscores, and hyphens")
 	}
 	return nil
@@ -52,3 +51,30 @@ func ValidateRequired(field, value string) error {
 	}
 	return nil
 }
+
+func ValidateCoordinates(latitude, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if longitude < -180 || longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+var languageCodeRegex = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+func ValidateLanguageCode(code string) error {
+	if !languageCodeRegex.MatchString(code) {
+		return fmt.Errorf("invalid language code %q, expected ISO 639 such as \"en\" or \"pt-BR\"", code)
+	}
+	return nil
+}
+
+func ValidateCEFRLevel(level string) error {
+	switch level {
+	case "A1", "A2", "B1", "B2", "C1", "C2":
+		return nil
+	}
+	return fmt.Errorf("invalid level %q, expected a CEFR level from A1 to C2", level)
+}